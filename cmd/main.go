@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
+	_ "time/tzdata" // Time zones of WhatsApp opening hours, absent from the alpine runtime image
 
 	"electronic-shop/config"
+	"electronic-shop/internal/events"
 	"electronic-shop/internal/handlers"
+	"electronic-shop/internal/jobs"
 	"electronic-shop/internal/middleware"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/notify"
+	"electronic-shop/internal/webhooks"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -16,6 +23,9 @@ import (
 func main() {
 	// Load environment variables
 	config.LoadEnv()
+	if err := handlers.CheckPublicBaseURL(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Ensure uploads directory exists
 	if err := os.MkdirAll("uploads", 0755); err != nil {
@@ -25,36 +35,93 @@ func main() {
 	// Connect to database
 	db := config.ConnectDB()
 
+	// Convert legacy float amounts before AutoMigrate touches the columns
+	if err := config.MigrateMoneyColumns(db); err != nil {
+		log.Fatalf("Money migration failed: %v", err)
+	}
+
 	// Auto-migrate all models
 	if err := db.AutoMigrate(
 		&models.Shop{},
+		&models.ShopSlugRedirect{},
+		&models.WhatsAppTemplate{},
+		&models.WhatsAppClick{},
+		&models.WhatsAppContact{},
+		&models.WhatsAppRoute{},
+		&models.TaxRate{},
 		&models.User{},
 		&models.Product{},
+		&models.ProductImage{},
 		&models.Transaction{},
+		&models.Payment{},
+		&models.InvoiceCounter{},
+		&models.Promotion{},
+		&models.Reservation{},
+		&models.NotificationSubscription{},
+		&models.StockAlert{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.WebhookAttempt{},
+		&models.DataMigration{},
 	); err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
+	if err := config.MigrateData(db); err != nil {
+		log.Fatalf("Data migration failed: %v", err)
+	}
+	if err := config.MigrateShopSlugs(db); err != nil {
+		log.Fatalf("Shop slug migration failed: %v", err)
+	}
+	if err := config.MigratePhoneNumbers(db); err != nil {
+		log.Fatalf("Phone number migration failed: %v", err)
+	}
+
+	// Background jobs
+	go jobs.Every(context.Background(), "reservations", time.Minute, jobs.ExpireReservations(db))
+	go jobs.Every(context.Background(), "webhooks", 10*time.Second, webhooks.NewSender(db).DeliverDue)
 
 	// Initialize Gin router
 	r := gin.Default()
+	// Route on the escaped path: storefront categories may contain "/" (/s/:slug/c/TV%2FAudio)
+	r.UseRawPath = true
 
 	// CORS configuration
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: false,
 	}))
 
+	// Stock alerts (channels configured from the environment)
+	stockAlerts := notify.NewStockAlerter(db, notify.FromEnv())
+
+	// Live events of the SSE stream (in-process: one instance)
+	hub := events.NewMemoryHub()
+
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(db)
 	shopHandler := handlers.NewShopHandler(db)
-	productHandler := handlers.NewProductHandler(db)
-	transactionHandler := handlers.NewTransactionHandler(db)
+	productHandler := handlers.NewProductHandler(db, stockAlerts, hub)
+	transactionHandler := handlers.NewTransactionHandler(db, stockAlerts, hub)
 	reportHandler := handlers.NewReportHandler(db)
 	publicHandler := handlers.NewPublicHandler(db)
 	uploadHandler := handlers.NewUploadHandler(db)
+	galleryHandler := handlers.NewGalleryHandler(db)
+	storefrontHandler := handlers.NewStorefrontHandler(db)
+	whatsAppTemplateHandler := handlers.NewWhatsAppTemplateHandler(db)
+	whatsAppContactHandler := handlers.NewWhatsAppContactHandler(db)
+	promotionHandler := handlers.NewPromotionHandler(db)
+	receiptHandler := handlers.NewReceiptHandler(db)
+	exportHandler := handlers.NewExportHandler(db)
+	importHandler := handlers.NewImportHandler(db, stockAlerts, hub)
+	bulkProductHandler := handlers.NewBulkProductHandler(db, stockAlerts, hub)
+	trashHandler := handlers.NewTrashHandler(db)
+	reservationHandler := handlers.NewReservationHandler(db, stockAlerts, hub)
+	notificationHandler := handlers.NewNotificationHandler(db, stockAlerts)
+	webhookHandler := handlers.NewWebhookHandler(db)
+	streamHandler := handlers.NewStreamHandler(hub)
 
 	// Serve uploaded images as static files
 	r.Static("/uploads", "./uploads")
@@ -65,7 +132,20 @@ func main() {
 	public := r.Group("/public")
 	{
 		public.GET("/:shopID/products", publicHandler.GetPublicProducts)
+		public.GET("/:shopID/products/:productID", publicHandler.GetPublicProduct)
+		public.GET("/:shopID/categories", publicHandler.GetPublicCategories)
 		public.GET("/:shopID/products/:productID/whatsapp", publicHandler.GetWhatsAppLink)
+		public.GET("/:shopID/products/:productID/whatsapp/redirect", publicHandler.RedirectToWhatsApp)
+	}
+
+	// ========================
+	// STOREFRONT (HTML, no auth)
+	// ========================
+	store := r.Group("/s")
+	{
+		store.GET("/:slug", storefrontHandler.Home)
+		store.GET("/:slug/c/:category", storefrontHandler.Category)
+		store.GET("/:slug/p/:productID", storefrontHandler.Product)
 	}
 
 	// ========================
@@ -89,6 +169,20 @@ func main() {
 		{
 			shops.GET("", shopHandler.GetShop)
 			shops.PUT("/whatsapp", shopHandler.UpdateWhatsApp)
+			shops.GET("/whatsapp-templates", whatsAppTemplateHandler.GetTemplates)
+			shops.PUT("/whatsapp-templates/:lang", whatsAppTemplateHandler.PutTemplate)
+			shops.DELETE("/whatsapp-templates/:lang", whatsAppTemplateHandler.DeleteTemplate)
+			shops.PUT("/language", whatsAppTemplateHandler.UpdateDefaultLanguage)
+			shops.GET("/whatsapp-contacts", whatsAppContactHandler.GetContacts)
+			shops.POST("/whatsapp-contacts", whatsAppContactHandler.CreateContact)
+			shops.PUT("/whatsapp-contacts/:id", whatsAppContactHandler.UpdateContact)
+			shops.DELETE("/whatsapp-contacts/:id", whatsAppContactHandler.DeleteContact)
+			shops.PUT("/whatsapp-routing", whatsAppContactHandler.UpdateRouting)
+			shops.GET("/slug", shopHandler.GetSlug)
+			shops.PUT("/slug", shopHandler.UpdateSlug)
+			shops.GET("/taxes", shopHandler.GetTaxSettings)
+			shops.PUT("/taxes", shopHandler.UpdateTaxSettings)
+			shops.PUT("/inventory", shopHandler.UpdateInventorySettings)
 		}
 
 		// Products (SuperAdmin + Admin)
@@ -96,9 +190,28 @@ func main() {
 		{
 			products.GET("", productHandler.GetProducts)
 			products.GET("/:id", productHandler.GetProduct)
+			products.GET("/reorder-suggestions", reportHandler.GetReorderSuggestions)
 			products.POST("", productHandler.CreateProduct)
+			products.POST("/import", importHandler.ImportProducts)
+			products.POST("/bulk/reprice", bulkProductHandler.Reprice)
+			products.POST("/bulk/stock", bulkProductHandler.AdjustStock)
+			products.POST("/bulk/delete", bulkProductHandler.Delete)
+			products.POST("/bulk/restore", bulkProductHandler.Restore)
 			products.PUT("/:id", productHandler.UpdateProduct)
+			products.PATCH("/:id", productHandler.UpdateProduct)
 			products.DELETE("/:id", productHandler.DeleteProduct)
+
+			// Gallery images, shown on the public product page
+			products.GET("/:id/images", galleryHandler.GetImages)
+			products.POST("/:id/images", galleryHandler.AddImage)
+			products.PUT("/:id/images/order", galleryHandler.ReorderImages)
+			products.DELETE("/:id/images/:imageID", galleryHandler.DeleteImage)
+
+			// Trash: soft-deleted products (purge: SuperAdmin only)
+			products.GET("/trash", trashHandler.GetTrash)
+			products.POST("/:id/restore", trashHandler.RestoreProduct)
+			products.DELETE("/trash", middleware.CheckRole("SuperAdmin"), trashHandler.PurgeTrash)
+			products.DELETE("/trash/:id", middleware.CheckRole("SuperAdmin"), trashHandler.PurgeProduct)
 		}
 
 		// Transactions (SuperAdmin + Admin)
@@ -106,6 +219,59 @@ func main() {
 		{
 			transactions.GET("", transactionHandler.GetTransactions)
 			transactions.POST("", transactionHandler.CreateTransaction)
+			transactions.GET("/:id/receipt", receiptHandler.GetReceipt)
+		}
+
+		// Reservations (SuperAdmin + Admin)
+		reservations := api.Group("/reservations")
+		{
+			reservations.GET("", reservationHandler.GetReservations)
+			reservations.POST("", reservationHandler.CreateReservation)
+			reservations.POST("/:id/pickup", reservationHandler.PickupReservation)
+			reservations.POST("/:id/cancel", reservationHandler.CancelReservation)
+		}
+
+		// Promotions and coupons (SuperAdmin only)
+		promotions := api.Group("/promotions")
+		promotions.Use(middleware.CheckRole("SuperAdmin"))
+		{
+			promotions.GET("", promotionHandler.GetPromotions)
+			promotions.POST("", promotionHandler.CreatePromotion)
+			promotions.PUT("/:id", promotionHandler.UpdatePromotion)
+			promotions.DELETE("/:id", promotionHandler.DeletePromotion)
+		}
+
+		// Stock alert notifications (SuperAdmin only)
+		notifications := api.Group("/notifications")
+		notifications.Use(middleware.CheckRole("SuperAdmin"))
+		{
+			notifications.GET("", notificationHandler.GetSubscriptions)
+			notifications.POST("", notificationHandler.CreateSubscription)
+			notifications.PUT("/:id", notificationHandler.UpdateSubscription)
+			notifications.DELETE("/:id", notificationHandler.DeleteSubscription)
+			notifications.POST("/:id/test", notificationHandler.TestSubscription)
+		}
+
+		// Outbound webhooks (SuperAdmin only)
+		hooks := api.Group("/webhooks")
+		hooks.Use(middleware.CheckRole("SuperAdmin"))
+		{
+			hooks.GET("", webhookHandler.GetEndpoints)
+			hooks.POST("", webhookHandler.CreateEndpoint)
+			hooks.PUT("/:id", webhookHandler.UpdateEndpoint)
+			hooks.DELETE("/:id", webhookHandler.DeleteEndpoint)
+			hooks.POST("/:id/rotate-secret", webhookHandler.RotateSecret)
+			hooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+			hooks.GET("/deliveries/:id", webhookHandler.GetDelivery)
+			hooks.POST("/deliveries/:id/redeliver", webhookHandler.Redeliver)
+		}
+
+		// Exports CSV / XLSX (report: SuperAdmin only)
+		exports := api.Group("/exports")
+		{
+			exports.GET("/transactions", exportHandler.ExportTransactions)
+			exports.GET("/products", exportHandler.ExportProducts)
+			exports.GET("/report", middleware.CheckRole("SuperAdmin"), exportHandler.ExportReport)
 		}
 
 		// Users management (SuperAdmin only)
@@ -117,6 +283,9 @@ func main() {
 			users.DELETE("/:id", handlers.NewUserHandler(db).DeleteUser)
 		}
 
+		// Live events, Server-Sent Events (SuperAdmin + Admin)
+		api.GET("/stream", streamHandler.Stream)
+
 		// Image upload (SuperAdmin + Admin)
 		api.POST("/upload/image", uploadHandler.UploadImage)
 
@@ -125,6 +294,9 @@ func main() {
 		reports.Use(middleware.CheckRole("SuperAdmin"))
 		{
 			reports.GET("/dashboard", reportHandler.GetDashboard)
+			reports.GET("/payments", reportHandler.GetPaymentReport)
+			reports.GET("/tax", reportHandler.GetTaxReport)
+			reports.GET("/whatsapp", reportHandler.GetWhatsAppReport)
 		}
 	}
 
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/phone"
+	"electronic-shop/internal/slug"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// moneyColumns held float64 amounts before money.Amount (integer hundredths)
+var moneyColumns = []struct{ table, column string }{
+	{"products", "purchase_price"},
+	{"products", "selling_price"},
+	{"transactions", "amount"},
+	{"transactions", "unit_price"},
+	{"transactions", "discount"},
+	{"transactions", "net_amount"},
+	{"transactions", "tax_amount"},
+	{"payments", "amount"},
+}
+
+// MigrateMoneyColumns converts legacy floating-point amount columns to integer hundredths
+// Must run BEFORE AutoMigrate: GORM would otherwise cast 1299.99 to 1300 instead of 129999
+// Runs in one SQL transaction so a failure leaves every column untouched
+func MigrateMoneyColumns(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, mc := range moneyColumns {
+			var dataType string
+			tx.Raw(`SELECT data_type FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`,
+				mc.table, mc.column).Scan(&dataType)
+
+			// Missing (fresh database) or already converted
+			if !isLegacyMoneyType(dataType) {
+				continue
+			}
+
+			if err := tx.Exec(moneyColumnConversion(mc.table, mc.column)).Error; err != nil {
+				return fmt.Errorf("converting %s.%s: %w", mc.table, mc.column, err)
+			}
+		}
+		return nil
+	})
+}
+
+// isLegacyMoneyType reports whether a column of this information_schema data type still holds decimal amounts
+func isLegacyMoneyType(dataType string) bool {
+	return dataType == "double precision" || dataType == "real" || dataType == "numeric"
+}
+
+// moneyColumnConversion returns the statement converting a decimal amount column to hundredths
+// numeric ROUND is exact and rounds half away from zero, like money.Parse
+func moneyColumnConversion(table, column string) string {
+	return fmt.Sprintf(
+		`ALTER TABLE %s ALTER COLUMN %s TYPE bigint USING ROUND(%s::numeric * 100)::bigint`,
+		table, column, column,
+	)
+}
+
+// MigrateData backfills data after AutoMigrate has added new columns
+// Every statement must be idempotent: it runs on each startup
+func MigrateData(db *gorm.DB) error {
+	statements := []string{
+		// Transactions recorded before VAT support: amount was tax-free
+		`UPDATE transactions SET net_amount = amount
+		 WHERE net_amount = 0 AND tax_amount = 0 AND amount <> 0`,
+		// Products created before tax classes
+		`UPDATE products SET tax_class = 'standard' WHERE tax_class IS NULL OR tax_class = ''`,
+		// SKUs are optional but unique per shop; deleted products release theirs
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_products_shop_sku ON products (shop_id, sku)
+		 WHERE sku <> '' AND deleted_at IS NULL`,
+		// Sales recorded before the product name snapshot
+		`UPDATE transactions t SET product_name = p.name FROM products p
+		 WHERE t.product_id = p.id AND (t.product_name IS NULL OR t.product_name = '')`,
+		// Shops created before currencies were configurable
+		`UPDATE shops SET currency = 'MAD' WHERE currency IS NULL OR currency = ''`,
+		// Sales recorded before invoice numbering, numbered in chronological order after any issued number
+		`UPDATE transactions t SET invoice_number = n.num
+		 FROM (
+			SELECT tr.id, COALESCE(ic.last_number, 0) + ROW_NUMBER() OVER (PARTITION BY tr.shop_id ORDER BY tr.created_at, tr.id) AS num
+			FROM transactions tr LEFT JOIN invoice_counters ic ON ic.shop_id = tr.shop_id
+			WHERE tr.type = 'Sale' AND tr.invoice_number IS NULL
+		 ) n
+		 WHERE t.id = n.id`,
+		`INSERT INTO invoice_counters (shop_id, last_number)
+		 SELECT shop_id, MAX(invoice_number) FROM transactions WHERE invoice_number IS NOT NULL GROUP BY shop_id
+		 ON CONFLICT (shop_id) DO UPDATE SET last_number = GREATEST(invoice_counters.last_number, EXCLUDED.last_number)`,
+		// Webhook attempts used to keep the endpoint's answer, shown back to the shop
+		`ALTER TABLE webhook_attempts DROP COLUMN IF EXISTS response_body`,
+		// Public search: French stemming, accent-insensitive ("ecran" finds "Écran")
+		`CREATE EXTENSION IF NOT EXISTS unaccent`,
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'french_unaccent') THEN
+				CREATE TEXT SEARCH CONFIGURATION french_unaccent (COPY = french);
+				ALTER TEXT SEARCH CONFIGURATION french_unaccent
+					ALTER MAPPING FOR hword, hword_part, word WITH unaccent, french_stem;
+			END IF;
+		 END $$`,
+		`CREATE INDEX IF NOT EXISTS idx_products_search ON products USING GIN (` + models.ProductSearchVector + `)`,
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateShopSlugs gives a slug, made from their name, to shops created before slugs
+func MigrateShopSlugs(db *gorm.DB) error {
+	var shops []models.Shop
+	if err := db.Where("slug IS NULL OR slug = ''").Order("created_at").Find(&shops).Error; err != nil {
+		return err
+	}
+
+	for _, shop := range shops {
+		s := slug.Unique(shop.Name, func(candidate string) bool {
+			var n int64
+			db.Raw(`SELECT (SELECT COUNT(*) FROM shops WHERE slug = ?) + (SELECT COUNT(*) FROM shop_slug_redirects WHERE slug = ?)`,
+				candidate, candidate).Scan(&n)
+			return n > 0
+		})
+		if err := db.Model(&models.Shop{}).Where("id = ?", shop.ID).Update("slug", s).Error; err != nil {
+			return fmt.Errorf("slug of shop %s: %w", shop.ID, err)
+		}
+	}
+	return nil
+}
+
+// phoneNumbersMigration - name of MigratePhoneNumbers in the data_migrations table
+const phoneNumbersMigration = "phone_numbers_e164"
+
+// MigratePhoneNumbers normalizes the WhatsApp numbers stored before E.164, read in each shop's country
+// It runs once: numbers that cannot be parsed are left as they are and logged that one time, for the
+// shop to fix them (the API only accepts valid numbers since)
+func MigratePhoneNumbers(db *gorm.DB) error {
+	var done int64
+	if err := db.Model(&models.DataMigration{}).Where("name = ?", phoneNumbersMigration).Count(&done).Error; err != nil {
+		return err
+	}
+	if done > 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var shops []models.Shop
+		if err := tx.Where("whats_app_number NOT LIKE '+%'").Find(&shops).Error; err != nil {
+			return err
+		}
+		countries := map[uuid.UUID]string{}
+		for _, shop := range shops {
+			countries[shop.ID] = shop.Country
+			number, err := phone.Normalize(shop.WhatsAppNumber, cmp.Or(shop.Country, phone.DefaultCountry))
+			if err != nil {
+				log.Printf("WhatsApp number of shop %s left as is: %v", shop.ID, err)
+				continue
+			}
+			if err := tx.Model(&models.Shop{}).Where("id = ?", shop.ID).Update("whats_app_number", number).Error; err != nil {
+				return fmt.Errorf("WhatsApp number of shop %s: %w", shop.ID, err)
+			}
+		}
+
+		var contacts []models.WhatsAppContact
+		if err := tx.Where("number NOT LIKE '+%'").Find(&contacts).Error; err != nil {
+			return err
+		}
+		for _, contact := range contacts {
+			country, known := countries[contact.ShopID]
+			if !known {
+				tx.Model(&models.Shop{}).Select("country").Where("id = ?", contact.ShopID).Scan(&country)
+				countries[contact.ShopID] = country
+			}
+			number, err := phone.Normalize(contact.Number, cmp.Or(country, phone.DefaultCountry))
+			if err != nil {
+				log.Printf("WhatsApp contact %s left as is: %v", contact.ID, err)
+				continue
+			}
+			if err := tx.Model(&models.WhatsAppContact{}).Where("id = ?", contact.ID).Update("number", number).Error; err != nil {
+				return fmt.Errorf("WhatsApp contact %s: %w", contact.ID, err)
+			}
+		}
+
+		return tx.Create(&models.DataMigration{Name: phoneNumbersMigration}).Error
+	})
+}
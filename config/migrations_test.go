@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestIsLegacyMoneyType(t *testing.T) {
+	tests := []struct {
+		dataType string
+		want     bool
+	}{
+		{"double precision", true},
+		{"real", true},
+		{"numeric", true},
+		{"bigint", false}, // Already converted
+		{"", false},       // Missing column: fresh database
+	}
+	for _, tt := range tests {
+		if got := isLegacyMoneyType(tt.dataType); got != tt.want {
+			t.Errorf("isLegacyMoneyType(%q) = %v, want %v", tt.dataType, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyColumnConversion(t *testing.T) {
+	want := `ALTER TABLE products ALTER COLUMN selling_price TYPE bigint USING ROUND(selling_price::numeric * 100)::bigint`
+	if got := moneyColumnConversion("products", "selling_price"); got != want {
+		t.Errorf("moneyColumnConversion = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,12 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"electronic-shop/internal/money"
+
+	"github.com/google/uuid"
+)
 
 // ========================
 // AUTH DTOs
@@ -13,6 +19,8 @@ type RegisterRequest struct {
 	Role           string `json:"role" binding:"required,oneof=SuperAdmin Admin"`
 	ShopName       string `json:"shop_name"`       // Required only if first SuperAdmin
 	WhatsAppNumber string `json:"whatsapp_number"` // Required only if creating new shop
+	Currency       string `json:"currency"`        // ISO 4217 code of the new shop, defaults to MAD
+	Country        string `json:"country"`         // ISO 3166 code of the new shop's phone numbers, defaults to MA
 	ShopID         string `json:"shop_id"`         // Provide existing ShopID to join a shop
 }
 
@@ -39,7 +47,59 @@ type UserResponse struct {
 // ========================
 
 type UpdateWhatsAppRequest struct {
-	WhatsAppNumber string `json:"whatsapp_number" binding:"required"`
+	WhatsAppNumber string `json:"whatsapp_number" binding:"required"` // International, or national in the shop's country
+	Country        string `json:"country"`                            // Changes the shop's country; empty keeps it
+}
+
+type UpdateWhatsAppTemplateRequest struct {
+	Body string `json:"body" binding:"required"` // Placeholders: {product_name}, {price}, {product_url}, {sku}, {shop_name}
+}
+
+type UpdateDefaultLanguageRequest struct {
+	DefaultLanguage string `json:"default_language" binding:"required"`
+}
+
+// WhatsAppContactRequest - a labelled WhatsApp number of the shop, e.g. "Téléphonie"
+type WhatsAppContactRequest struct {
+	Label  string `json:"label" binding:"required,max=60"`
+	Number string `json:"number" binding:"required"` // International, or national in the shop's country
+}
+
+// UpdateWhatsAppRoutingRequest - replaces every route of the shop; they are tried in this order
+type UpdateWhatsAppRoutingRequest struct {
+	Timezone string                 `json:"timezone"` // IANA name of the opening hours, e.g. Africa/Casablanca; empty keeps the current one
+	Routes   []WhatsAppRouteRequest `json:"routes" binding:"dive"`
+}
+
+type WhatsAppRouteRequest struct {
+	ContactID uuid.UUID `json:"contact_id" binding:"required"`
+	Category  string    `json:"category"` // Empty = every category
+	Days      string    `json:"days"`     // e.g. "mon-fri,sat", empty = every day
+	Opens     string    `json:"opens"`    // "HH:MM", both empty = all day
+	Closes    string    `json:"closes"`
+}
+
+type UpdateSlugRequest struct {
+	Slug string `json:"slug" binding:"required"`
+}
+
+// UpdateTaxSettingsRequest - omitted flags are left unchanged; rates, when sent, replace all classes
+type UpdateTaxSettingsRequest struct {
+	PricesIncludeTax    *bool            `json:"prices_include_tax"`
+	DisplayTaxInclusive *bool            `json:"display_tax_inclusive"`
+	Rates               []TaxRateRequest `json:"rates" binding:"omitempty,dive"`
+}
+
+// UpdateInventorySettingsRequest - omitted fields are left unchanged
+type UpdateInventorySettingsRequest struct {
+	DefaultReorderPoint *int `json:"default_reorder_point" binding:"omitempty,min=0"`
+	SalesVelocityDays   *int `json:"sales_velocity_days" binding:"omitempty,min=7,max=365"`
+}
+
+type TaxRateRequest struct {
+	Class string  `json:"class" binding:"required,max=30"`
+	Name  string  `json:"name"`
+	Rate  float64 `json:"rate" binding:"min=0,max=100"`
 }
 
 // ========================
@@ -47,49 +107,200 @@ type UpdateWhatsAppRequest struct {
 // ========================
 
 type CreateProductRequest struct {
-	Name          string  `json:"name" binding:"required,min=1"`
-	Description   string  `json:"description"`
-	Category      string  `json:"category"`
-	PurchasePrice float64 `json:"purchase_price"`
-	SellingPrice  float64 `json:"selling_price" binding:"required,gt=0"`
-	Stock         int     `json:"stock" binding:"min=0"`
-	ImageURL      string  `json:"image_url"`
+	SKU             string       `json:"sku" binding:"max=64"`
+	Name            string       `json:"name" binding:"required,min=1"`
+	Description     string       `json:"description"`
+	Category        string       `json:"category"`
+	PurchasePrice   money.Amount `json:"purchase_price" binding:"min=0"`
+	SellingPrice    money.Amount `json:"selling_price" binding:"required,gt=0"`
+	Stock           int          `json:"stock" binding:"min=0"`
+	TaxClass        string       `json:"tax_class"` // Defaults to "standard"
+	ImageURL        string       `json:"image_url"`
+	ReorderPoint    *int         `json:"reorder_point" binding:"omitempty,min=0"` // Defaults to the shop's
+	ReorderQuantity int          `json:"reorder_quantity" binding:"min=0"`
 }
 
+// UpdateProductRequest - partial update: omitted fields are left unchanged
+// Present fields are applied as given, so "" clears a description and 0 sets the stock to zero
 type UpdateProductRequest struct {
-	Name          string  `json:"name"`
-	Description   string  `json:"description"`
-	Category      string  `json:"category"`
-	PurchasePrice float64 `json:"purchase_price"`
-	SellingPrice  float64 `json:"selling_price"`
-	Stock         int     `json:"stock"`
-	ImageURL      string  `json:"image_url"`
+	SKU             *string       `json:"sku" binding:"omitempty,max=64"` // "" removes the SKU
+	Name            *string       `json:"name" binding:"omitempty,min=1"`
+	Description     *string       `json:"description"`
+	Category        *string       `json:"category"`
+	PurchasePrice   *money.Amount `json:"purchase_price" binding:"omitempty,min=0"`
+	SellingPrice    *money.Amount `json:"selling_price" binding:"omitempty,gt=0"`
+	Stock           *int          `json:"stock" binding:"omitempty,min=0"`
+	TaxClass        *string       `json:"tax_class"` // "" resets to "standard"
+	ImageURL        *string       `json:"image_url"`
+	ReorderPoint    *int          `json:"reorder_point" binding:"omitempty,min=-1"` // -1 goes back to the shop default
+	ReorderQuantity *int          `json:"reorder_quantity" binding:"omitempty,min=0"`
 }
 
 // PrivateProductResponse - for authenticated users
 type PrivateProductResponse struct {
-	ID            uuid.UUID `json:"id"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	Category      string    `json:"category"`
-	PurchasePrice float64   `json:"purchase_price"` // Only SuperAdmin sees this (filtered in handler)
-	SellingPrice  float64   `json:"selling_price"`
-	Stock         int       `json:"stock"`
-	ImageURL      string    `json:"image_url"`
-	ShopID        uuid.UUID `json:"shop_id"`
+	ID              uuid.UUID    `json:"id"`
+	SKU             string       `json:"sku"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	Category        string       `json:"category"`
+	PurchasePrice   money.Amount `json:"purchase_price"` // Only SuperAdmin sees this (filtered in handler)
+	SellingPrice    money.Amount `json:"selling_price"`
+	Stock           int          `json:"stock"`
+	TaxClass        string       `json:"tax_class"`
+	ImageURL        string       `json:"image_url"`
+	ReorderPoint    *int         `json:"reorder_point"` // null: the shop default applies
+	ReorderQuantity int          `json:"reorder_quantity"`
+	Version         int          `json:"version"`
+	ShopID          uuid.UUID    `json:"shop_id"`
+}
+
+// AddProductImageRequest - url as returned by POST /api/upload/image, or an absolute http(s) URL
+type AddProductImageRequest struct {
+	URL      string `json:"url" binding:"required,max=500"`
+	AltText  string `json:"alt_text" binding:"max=200"`
+	Position *int   `json:"position" binding:"omitempty,min=0"` // Defaults to after the last image
+}
+
+// ReorderProductImagesRequest - every image ID of the product, in the new gallery order
+type ReorderProductImagesRequest struct {
+	ImageIDs []uuid.UUID `json:"image_ids" binding:"required"`
+}
+
+// TrashedProductResponse - a soft-deleted product, purgeable by SuperAdmin from PurgeableAt
+type TrashedProductResponse struct {
+	PrivateProductResponse
+	DeletedAt   time.Time `json:"deleted_at"`
+	PurgeableAt time.Time `json:"purgeable_at"`
+}
+
+// ProductImportResponse - outcome of a CSV/XLSX import, one entry per data row
+// When Errors is not empty nothing was written, even outside dry-run mode
+type ProductImportResponse struct {
+	DryRun         bool               `json:"dry_run"`
+	TotalRows      int                `json:"total_rows"`
+	Created        int                `json:"created"`
+	Updated        int                `json:"updated"`
+	IgnoredColumns []string           `json:"ignored_columns"`
+	Rows           []ProductImportRow `json:"rows"`
+	Errors         []ImportRowError   `json:"errors"`
+}
+
+type ProductImportRow struct {
+	Line      int        `json:"line"`
+	Action    string     `json:"action"` // create | update | error
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	SKU       string     `json:"sku,omitempty"`
+	Name      string     `json:"name"`
+}
+
+type ImportRowError struct {
+	Line   int    `json:"line"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ProductFilter - selects the products of a bulk operation
+// IDs, when given, restrict the selection further; an empty filter matches the whole catalog
+type ProductFilter struct {
+	Category string      `json:"category"`
+	Search   string      `json:"search"`
+	IDs      []uuid.UUID `json:"ids" binding:"max=500"`
+}
+
+// BulkRepriceRequest - changes the selling price of every product matching the filter
+// percent: Value is a percentage (-10 lowers prices by 10%); fixed: Amount is added (negative to lower)
+type BulkRepriceRequest struct {
+	Filter ProductFilter `json:"filter"`
+	Mode   string        `json:"mode" binding:"required,oneof=percent fixed"`
+	Value  float64       `json:"value" binding:"min=-100,max=1000"`
+	Amount money.Amount  `json:"amount"`
+	DryRun bool          `json:"dry_run"`
+}
+
+// BulkStockRequest - inventory adjustments applied all together or not at all
+type BulkStockRequest struct {
+	Adjustments []StockAdjustment `json:"adjustments" binding:"required,min=1,max=500,dive"`
+}
+
+// StockAdjustment - either a relative Delta (+5 received, -2 broken) or the counted Stock
+type StockAdjustment struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Delta     *int      `json:"delta"`
+	Stock     *int      `json:"stock" binding:"omitempty,min=0"`
+}
+
+type BulkIDsRequest struct {
+	IDs []uuid.UUID `json:"ids" binding:"required,min=1,max=500"`
+}
+
+// BulkResult - per-product outcome of a bulk operation
+type BulkResult struct {
+	ProductID uuid.UUID     `json:"product_id"`
+	Name      string        `json:"name,omitempty"`
+	Status    string        `json:"status"` // updated | deleted | restored | skipped | not_found | error
+	OldPrice  *money.Amount `json:"old_price,omitempty"`
+	NewPrice  *money.Amount `json:"new_price,omitempty"`
+	OldStock  *int          `json:"old_stock,omitempty"`
+	NewStock  *int          `json:"new_stock,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	DryRun    bool         `json:"dry_run,omitempty"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []BulkResult `json:"results"`
+}
+
+// PublicProductQuery - filters, sorting and pagination of the public catalog (query string)
+// Without page nor per_page every matching product is returned
+type PublicProductQuery struct {
+	Q           string `form:"q" binding:"max=100"` // Full-text search in name, category and description
+	Category    string `form:"category"`
+	InStockOnly bool   `form:"in_stock_only"`
+	MinPrice    string `form:"min_price"` // Displayed (regular) price, e.g. 99.90
+	MaxPrice    string `form:"max_price"`
+	Sort        string `form:"sort" binding:"omitempty,oneof=relevance name price_asc price_desc newest popularity"`
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	PerPage     int    `form:"per_page" binding:"omitempty,min=1,max=100"`
 }
 
 // PublicProductResponse - NEVER exposes PurchasePrice
 type PublicProductResponse struct {
-	ID           uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	Description  string    `json:"description"`
-	Category     string    `json:"category"`
-	SellingPrice float64   `json:"selling_price"`
-	Stock        int       `json:"stock"`
-	StockStatus  string    `json:"stock_status"`
-	ImageURL     string    `json:"image_url"`
-	WhatsAppLink string    `json:"whatsapp_link"`
+	ID               uuid.UUID    `json:"id"`
+	Name             string       `json:"name"`
+	Description      string       `json:"description"`
+	Category         string       `json:"category"`
+	SellingPrice     money.Amount `json:"selling_price"`
+	PriceIncludesTax bool         `json:"price_includes_tax"` // Per the shop display setting, also for promotional_price
+	TaxRate          float64      `json:"tax_rate"`
+	Stock            int          `json:"stock"`
+	StockStatus      string       `json:"stock_status"`
+	ImageURL         string       `json:"image_url"`
+	WhatsAppLink     string       `json:"whatsapp_link"`
+	WhatsAppRedirect string       `json:"whatsapp_redirect"`           // Same conversation through the shop's server, which counts the inquiry
+	WhatsAppContact  string       `json:"whatsapp_contact,omitempty"`  // Label of the routed number, empty for the shop's main number
+	PromotionalPrice money.Amount `json:"promotional_price,omitempty"` // Set when an automatic promotion applies
+	Promotion        string       `json:"promotion,omitempty"`
+}
+
+// PublicProductDetailResponse - product page: the product, its gallery and related products
+type PublicProductDetailResponse struct {
+	PublicProductResponse
+	Images  []PublicProductImage    `json:"images"`
+	Related []PublicProductResponse `json:"related"` // Same category, available ones first
+}
+
+type PublicProductImage struct {
+	URL     string `json:"url"`
+	AltText string `json:"alt_text"`
+}
+
+// PublicCategoryResponse - a category of the catalog with its number of products
+type PublicCategoryResponse struct {
+	Name         string `json:"name"`
+	ProductCount int    `json:"product_count"`
+	InStockCount int    `json:"in_stock_count"` // Products with units not held by reservations
 }
 
 // ========================
@@ -97,11 +308,117 @@ type PublicProductResponse struct {
 // ========================
 
 type CreateTransactionRequest struct {
-	Type      string     `json:"type" binding:"required,oneof=Sale Expense Withdrawal"`
-	ProductID *uuid.UUID `json:"product_id"`
-	Quantity  int        `json:"quantity" binding:"min=0"`
-	Amount    float64    `json:"amount" binding:"required,gt=0"`
-	Comment   string     `json:"comment"`
+	Type          string           `json:"type" binding:"required,oneof=Sale Expense Withdrawal"`
+	ProductID     *uuid.UUID       `json:"product_id"`
+	Quantity      int              `json:"quantity" binding:"min=0"`
+	Amount        money.Amount     `json:"amount" binding:"omitempty,gt=0"` // Required for Expense/Withdrawal; computed by the server for Sale
+	Comment       string           `json:"comment"`
+	Payments      []PaymentRequest `json:"payments" binding:"omitempty,dive"` // Required for Sale, must sum to the amount
+	LineDiscount  *DiscountRequest `json:"line_discount"`                     // Sale only
+	OrderDiscount *DiscountRequest `json:"order_discount"`                    // Sale only, applied after the line discount
+	PriceOverride bool             `json:"price_override"`                    // SuperAdmin only: allow selling below purchase price
+	CouponCode    string           `json:"coupon_code"`                       // Sale only, unlocks a coupon promotion
+}
+
+type DiscountRequest struct {
+	Type   string       `json:"type" binding:"required,oneof=percent fixed"`
+	Value  float64      `json:"value" binding:"min=0,max=100"` // percent: percentage
+	Amount money.Amount `json:"amount" binding:"min=0"`        // fixed: amount off
+	Reason string       `json:"reason" binding:"required,min=3"`
+}
+
+type PaymentRequest struct {
+	Method string       `json:"method" binding:"required,oneof=Cash Card BankTransfer MobileMoney"`
+	Amount money.Amount `json:"amount" binding:"required,gt=0"`
+}
+
+// ========================
+// PROMOTION DTOs
+// ========================
+
+type PromotionRequest struct {
+	Name        string     `json:"name" binding:"required,min=2"`
+	Type        string     `json:"type" binding:"required,oneof=PercentOff FixedOff BuyXGetY"`
+	Value       float64    `json:"value" binding:"min=0"` // PercentOff: percentage, FixedOff: amount off each unit
+	BuyQuantity int        `json:"buy_quantity" binding:"min=0"`
+	GetQuantity int        `json:"get_quantity" binding:"min=0"`
+	Category    string     `json:"category"`
+	ProductID   *uuid.UUID `json:"product_id"`
+	CouponCode  string     `json:"coupon_code"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	MaxUses     int        `json:"max_uses" binding:"min=0"`
+	Active      *bool      `json:"active"` // Defaults to true
+}
+
+// ========================
+// RESERVATION DTOs
+// ========================
+
+type CreateReservationRequest struct {
+	ProductID     uuid.UUID `json:"product_id" binding:"required"`
+	Quantity      int       `json:"quantity" binding:"required,min=1"`
+	CustomerName  string    `json:"customer_name" binding:"required,min=2"`
+	CustomerPhone string    `json:"customer_phone"`
+	Note          string    `json:"note"`
+	HoldHours     int       `json:"hold_hours" binding:"omitempty,min=1,max=168"` // Defaults to RESERVATION_HOLD_HOURS
+}
+
+// PickupReservationRequest - the sale details of a pickup; product and quantity come from the reservation
+type PickupReservationRequest struct {
+	Payments      []PaymentRequest `json:"payments" binding:"required,min=1,dive"`
+	LineDiscount  *DiscountRequest `json:"line_discount"`
+	OrderDiscount *DiscountRequest `json:"order_discount"`
+	PriceOverride bool             `json:"price_override"`
+	CouponCode    string           `json:"coupon_code"`
+	Comment       string           `json:"comment"`
+}
+
+// ========================
+// NOTIFICATION DTOs
+// ========================
+
+// CreateNotificationSubscriptionRequest - target: e-mail address, http(s) URL or WhatsApp number, depending on the channel
+type CreateNotificationSubscriptionRequest struct {
+	Channel string `json:"channel" binding:"required,oneof=email webhook whatsapp log"`
+	Target  string `json:"target" binding:"max=500"`
+	Enabled *bool  `json:"enabled"` // Defaults to true
+}
+
+type UpdateNotificationSubscriptionRequest struct {
+	Target  *string `json:"target" binding:"omitempty,max=500"`
+	Enabled *bool   `json:"enabled"`
+}
+
+// ========================
+// WEBHOOK DTOs
+// ========================
+
+// CreateWebhookEndpointRequest - events: names from GET /api/webhooks, or "*" for all
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=500"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Description string   `json:"description" binding:"max=200"`
+	Active      *bool    `json:"active"` // Defaults to true
+}
+
+type UpdateWebhookEndpointRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url,max=500"`
+	Events      []string `json:"events" binding:"omitempty,min=1"`
+	Description *string  `json:"description" binding:"omitempty,max=200"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookEndpointResponse - the secret is only returned on creation and rotation
+type WebhookEndpointResponse struct {
+	ID          uuid.UUID `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // ========================
@@ -109,9 +426,10 @@ type CreateTransactionRequest struct {
 // ========================
 
 type DashboardResponse struct {
-	TotalSales        float64        `json:"total_sales"`
-	TotalExpenses     float64        `json:"total_expenses"`
-	NetProfit         float64        `json:"net_profit"`
+	Currency          string         `json:"currency"`
+	TotalSales        money.Amount   `json:"total_sales"`
+	TotalExpenses     money.Amount   `json:"total_expenses"`
+	NetProfit         money.Amount   `json:"net_profit"`
 	LowStockProducts  []LowStockItem `json:"low_stock_products"`
 	TotalProducts     int64          `json:"total_products"`
 	TotalTransactions int64          `json:"total_transactions"`
@@ -119,10 +437,93 @@ type DashboardResponse struct {
 }
 
 type LowStockItem struct {
-	ID       uuid.UUID `json:"id"`
-	Name     string    `json:"name"`
-	Stock    int       `json:"stock"`
-	Category string    `json:"category"`
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Stock        int       `json:"stock"`
+	ReorderPoint int       `json:"reorder_point"`
+	Category     string    `json:"category"`
+}
+
+// ReorderSuggestion - a product to reorder, with the sales pace behind the suggestion
+type ReorderSuggestion struct {
+	ProductID         uuid.UUID `json:"product_id"`
+	SKU               string    `json:"sku,omitempty"`
+	Name              string    `json:"name"`
+	Category          string    `json:"category"`
+	Stock             int       `json:"stock"`
+	Reserved          int       `json:"reserved"`
+	ReorderPoint      int       `json:"reorder_point"`
+	DailySales        float64   `json:"daily_sales"`
+	DaysUntilStockout *float64  `json:"days_until_stockout"` // null when nothing sold in the window
+	SuggestedQuantity int       `json:"suggested_quantity"`
+}
+
+// PaymentReportResponse - takings per payment method per day (end-of-day reconciliation)
+type PaymentReportResponse struct {
+	Days   []DailyPaymentSummary `json:"days"`
+	Totals []PaymentMethodTotal  `json:"totals"`
+	Total  money.Amount          `json:"total"`
+}
+
+type DailyPaymentSummary struct {
+	Date    string               `json:"date"`
+	Methods []PaymentMethodTotal `json:"methods"`
+	Total   money.Amount         `json:"total"`
+}
+
+// TaxReportResponse - VAT collected on sales over a period, per rate
+type TaxReportResponse struct {
+	DateFrom  string          `json:"date_from,omitempty"`
+	DateTo    string          `json:"date_to,omitempty"`
+	Rates     []TaxRateTotals `json:"rates"`
+	NetAmount money.Amount    `json:"net_amount"`
+	TaxAmount money.Amount    `json:"tax_amount"`
+	Gross     money.Amount    `json:"gross_amount"`
+}
+
+type TaxRateTotals struct {
+	TaxRate   float64      `json:"tax_rate"`
+	NetAmount money.Amount `json:"net_amount"`
+	TaxAmount money.Amount `json:"tax_amount"`
+	Gross     money.Amount `json:"gross_amount"`
+	Count     int64        `json:"count"`
+}
+
+type PaymentMethodTotal struct {
+	Method string       `json:"method"`
+	Amount money.Amount `json:"amount"`
+	Count  int64        `json:"count"`
+}
+
+// WhatsAppReportResponse - WhatsApp inquiries over a period and how many ended in a sale
+// An inquiry is converted when its product is sold within attribution_days after the click
+type WhatsAppReportResponse struct {
+	DateFrom        string `json:"date_from"`
+	DateTo          string `json:"date_to,omitempty"`
+	Interval        string `json:"interval"` // day, week or month
+	AttributionDays int    `json:"attribution_days"`
+	WhatsAppLeadStats
+	Products []WhatsAppProductStats `json:"products"` // Most inquiries first
+	Periods  []WhatsAppPeriodStats  `json:"periods"`  // Oldest first
+}
+
+type WhatsAppLeadStats struct {
+	Inquiries      int64   `json:"inquiries"`
+	UniqueVisitors int64   `json:"unique_visitors"`
+	Converted      int64   `json:"converted"`
+	ConversionRate float64 `json:"conversion_rate"` // Converted / inquiries, in %
+}
+
+type WhatsAppProductStats struct {
+	ProductID   *uuid.UUID `json:"product_id"` // null once the product is purged
+	ProductName string     `json:"product_name"`
+	WhatsAppLeadStats
+	LastInquiryAt time.Time `json:"last_inquiry_at"`
+}
+
+type WhatsAppPeriodStats struct {
+	Period string `json:"period"` // First day of the period, YYYY-MM-DD
+	WhatsAppLeadStats
 }
 
 // ========================
@@ -0,0 +1,45 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvWriter writes RFC 4180 CSV, preceded by a UTF-8 BOM so Excel shows accents correctly
+type csvWriter struct {
+	w       *csv.Writer
+	started bool
+	out     io.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w), out: w}
+}
+
+func (c *csvWriter) WriteRow(cells ...Cell) error {
+	if !c.started {
+		c.started = true
+		if _, err := io.WriteString(c.out, "\ufeff"); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = cell.value
+		if !cell.numeric {
+			record[i] = escapeFormula(cell.value)
+		}
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	// Push rows to the client as we go instead of buffering the whole file
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
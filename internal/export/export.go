@@ -0,0 +1,96 @@
+// Package export streams tabular data as CSV or XLSX.
+//
+// Rows are written as they are produced, so an export of any size only keeps
+// one row in memory.
+package export
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"electronic-shop/internal/money"
+)
+
+// Supported formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ErrUnknownFormat is returned by New for anything but csv or xlsx
+var ErrUnknownFormat = errors.New("format must be csv or xlsx")
+
+// Writer receives the rows of one sheet
+// Close must be called to flush the file (the XLSX footer is written then)
+type Writer interface {
+	WriteRow(cells ...Cell) error
+	Close() error
+}
+
+// Cell - one typed value; XLSX keeps numbers numeric so the accountant can sum them
+type Cell struct {
+	value   string
+	numeric bool
+}
+
+// Text makes a text cell
+func Text(s string) Cell {
+	return Cell{value: s}
+}
+
+// Money makes a numeric cell with two decimals
+func Money(a money.Amount) Cell {
+	return Cell{value: a.String(), numeric: true}
+}
+
+// Int makes a numeric cell
+func Int(i int64) Cell {
+	return Cell{value: strconv.FormatInt(i, 10), numeric: true}
+}
+
+// Float makes a numeric cell (rates, percentages)
+func Float(f float64) Cell {
+	return Cell{value: strconv.FormatFloat(f, 'f', -1, 64), numeric: true}
+}
+
+// Time makes a text cell in a format both Excel and LibreOffice recognize
+func Time(t time.Time) Cell {
+	return Cell{value: t.Format("2006-01-02 15:04:05")}
+}
+
+// formulaTriggers - first characters that make spreadsheet programs read a text as a formula
+const formulaTriggers = "=+-@\t\r"
+
+// escapeFormula neutralizes user text such as `=HYPERLINK(...)` with a leading quote,
+// so an exported file cannot run a formula when opened (CSV/formula injection)
+// Only CSV needs it: XLSX text cells are typed as strings and never evaluated
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaTriggers, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Header turns column names into text cells
+func Header(names ...string) []Cell {
+	cells := make([]Cell, len(names))
+	for i, n := range names {
+		cells[i] = Text(n)
+	}
+	return cells
+}
+
+// New returns a writer for the format, and the content type to send
+func New(format string, w io.Writer, sheetName string) (Writer, string, error) {
+	switch format {
+	case FormatCSV, "":
+		return newCSVWriter(w), "text/csv; charset=utf-8", nil
+	case FormatXLSX:
+		xw, err := newXLSXWriter(w, sheetName)
+		return xw, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", err
+	}
+	return nil, "", ErrUnknownFormat
+}
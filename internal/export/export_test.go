@@ -0,0 +1,148 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"electronic-shop/internal/money"
+)
+
+// hostileRow mixes user text that spreadsheets would evaluate with numeric cells
+var hostileRow = []Cell{
+	Text(`=HYPERLINK("http://evil.example","Cliquez")`),
+	Text("+212600000000"),
+	Text("-2+3"),
+	Text("@SUM(A1:A9)"),
+	Text("\t=1+1"),
+	Text("\r=1+1"),
+	Text("Écran 4K = top"),
+	Text(""),
+	Money(-350),
+	Int(-5),
+	Float(-0.5),
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"=1+1", "'=1+1"},
+		{"+33 6 12 34 56 78", "'+33 6 12 34 56 78"},
+		{"-10%", "'-10%"},
+		{"@cmd", "'@cmd"},
+		{"\tx", "'\tx"},
+		{"\rx", "'\rx"},
+		{"iPhone 15", "iPhone 15"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, _, err := New(FormatCSV, &buf, "Produits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(hostileRow...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff")))
+	got, err := r.Read()
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	want := []string{
+		`'=HYPERLINK("http://evil.example","Cliquez")`,
+		"'+212600000000",
+		"'-2+3",
+		"'@SUM(A1:A9)",
+		"'\t=1+1",
+		"'\r=1+1",
+		"Écran 4K = top",
+		"",
+		"-3.50",
+		"-5",
+		"-0.5",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CSV row = %q, want %q", got, want)
+	}
+}
+
+// XLSX text cells are inline strings, never evaluated: the text is written unchanged
+func TestXLSXKeepsText(t *testing.T) {
+	var buf bytes.Buffer
+	w, _, err := New(FormatXLSX, &buf, "Produits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(hostileRow...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(data)
+		}
+	}
+
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;http://evil.example&#34;,&#34;Cliquez&#34;)</t></is></c>`,
+		`<t xml:space="preserve">+212600000000</t>`,
+		`<t xml:space="preserve">-2+3</t>`,
+		`<t xml:space="preserve">@SUM(A1:A9)</t>`,
+		`<t xml:space="preserve">&#x9;=1+1</t>`,
+		`<t xml:space="preserve">&#xD;=1+1</t>`,
+		`<t xml:space="preserve">Écran 4K = top</t>`,
+		// Numbers stay numeric
+		`<c r="I1"><v>-3.50</v></c>`,
+		`<c r="J1"><v>-5</v></c>`,
+		`<c r="K1"><v>-0.5</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet does not contain %s\n%s", want, sheet)
+		}
+	}
+	if strings.Contains(sheet, "&#39;") {
+		t.Errorf("sheet has quote-escaped text\n%s", sheet)
+	}
+}
+
+func TestMoneyCellsStayNumeric(t *testing.T) {
+	for _, a := range []money.Amount{0, 129999, -1} {
+		if c := Money(a); !c.numeric || c.value != a.String() {
+			t.Errorf("Money(%d) = %+v, want numeric %s", a, c, a.String())
+		}
+	}
+}
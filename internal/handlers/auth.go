@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"cmp"
 	"net/http"
 	"os"
 	"time"
 
 	"electronic-shop/internal/dto"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/phone"
+	"electronic-shop/internal/slug"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -70,12 +74,43 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			return
 		}
 
+		currency := money.DefaultCurrency
+		if req.Currency != "" {
+			cur, ok := money.LookupCurrency(req.Currency)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency: " + req.Currency})
+				return
+			}
+			currency = cur.Code
+		}
+
+		country, ok := phone.LookupCountry(cmp.Or(req.Country, phone.DefaultCountry))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": phone.ErrCountry.Error()})
+			return
+		}
+		whatsAppNumber, err := phone.Normalize(req.WhatsAppNumber, country.Code)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "whatsapp_number: " + err.Error()})
+			return
+		}
+
 		shop := models.Shop{
-			Name:           req.ShopName,
-			WhatsAppNumber: req.WhatsAppNumber,
+			Name: req.ShopName,
+			// Public URL made from the name, editable later with PUT /api/shops/slug
+			Slug: slug.Unique(req.ShopName, func(s string) bool {
+				return shopSlugTaken(h.db, s, uuid.Nil)
+			}),
+			WhatsAppNumber: whatsAppNumber,
+			Country:        country.Code,
+			Currency:       currency,
 			Active:         true,
 		}
 		if err := h.db.Create(&shop).Error; err != nil {
+			if isUniqueViolation(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Shop slug already taken, please retry"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create shop"})
 			return
 		}
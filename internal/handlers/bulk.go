@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/events"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/notify"
+	"electronic-shop/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errBulkFailed rolls back a bulk transaction once per-item errors have been recorded
+var errBulkFailed = errors.New("bulk operation failed")
+
+type BulkProductHandler struct {
+	db     *gorm.DB
+	alerts *notify.StockAlerter
+	hub    events.Hub
+}
+
+func NewBulkProductHandler(db *gorm.DB, alerts *notify.StockAlerter, hub events.Hub) *BulkProductHandler {
+	return &BulkProductHandler{db: db, alerts: alerts, hub: hub}
+}
+
+func newBulkResponse(results []dto.BulkResult) dto.BulkResponse {
+	resp := dto.BulkResponse{Results: results}
+	if resp.Results == nil {
+		resp.Results = []dto.BulkResult{}
+	}
+	for _, r := range resp.Results {
+		switch r.Status {
+		case "updated", "deleted", "restored":
+			resp.Succeeded++
+		case "not_found", "error":
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
+// Reprice - changes the selling price of every product matching the filter
+// Prices are rounded to the shop currency; products whose price would not stay positive are skipped
+// With dry_run the new prices are computed but not saved
+func (h *BulkProductHandler) Reprice(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.BulkRepriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Mode == "percent" && req.Value == 0) || (req.Mode == "fixed" && req.Amount == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A non-zero value (percent) or amount (fixed) is required"})
+		return
+	}
+
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+	currency := money.CurrencyOrDefault(shop.Currency)
+
+	var results []dto.BulkResult
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		query := filterProducts(tx.Where("shop_id = ?", shopID), req.Filter.Category, req.Filter.Search)
+		if len(req.Filter.IDs) > 0 {
+			query = query.Where("id IN ?", req.Filter.IDs)
+		}
+
+		var products []models.Product
+		if err := query.Clauses(clause.Locking{Strength: "UPDATE"}).Order("name").Find(&products).Error; err != nil {
+			return err
+		}
+
+		for _, p := range products {
+			oldPrice := p.SellingPrice
+			newPrice := oldPrice + req.Amount
+			if req.Mode == "percent" {
+				newPrice = oldPrice + oldPrice.Percent(req.Value)
+			}
+			newPrice = currency.Round(newPrice)
+
+			result := dto.BulkResult{ProductID: p.ID, Name: p.Name, OldPrice: &oldPrice, NewPrice: &newPrice, Status: "updated"}
+			switch {
+			case newPrice <= 0:
+				result.Status = "skipped"
+				result.Error = "Price would not be positive"
+				result.NewPrice = nil
+			case newPrice == oldPrice:
+				result.Status = "skipped"
+				result.Error = "Price unchanged"
+			case !req.DryRun:
+				if err := tx.Model(&models.Product{}).Where("id = ? AND shop_id = ?", p.ID, shopID).
+					Updates(map[string]interface{}{"selling_price": newPrice, "version": gorm.Expr("version + 1")}).Error; err != nil {
+					return err
+				}
+				if err := emitProductEvent(tx, shopID, webhooks.EventProductUpdated, p.ID); err != nil {
+					return err
+				}
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update prices"})
+		return
+	}
+
+	resp := newBulkResponse(results)
+	resp.DryRun = req.DryRun
+	c.JSON(http.StatusOK, resp)
+}
+
+// AdjustStock - applies stock adjustments (delta or counted stock) to many products at once
+// All adjustments are saved together: if one fails (unknown product, negative stock) none is
+func (h *BulkProductHandler) AdjustStock(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.BulkStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var results []dto.BulkResult
+	failed := false
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		seen := map[uuid.UUID]bool{}
+
+		for _, adj := range req.Adjustments {
+			result := dto.BulkResult{ProductID: adj.ProductID, Status: "updated"}
+			fail := func(status, msg string) {
+				result.Status, result.Error = status, msg
+				failed = true
+			}
+
+			var product models.Product
+			switch {
+			case (adj.Delta == nil) == (adj.Stock == nil):
+				fail("error", "Exactly one of delta or stock is required")
+			case seen[adj.ProductID]:
+				fail("error", "Product listed more than once")
+			case tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND shop_id = ?", adj.ProductID, shopID).
+				First(&product).Error != nil:
+				fail("not_found", "Product not found")
+			default:
+				oldStock, newStock := product.Stock, product.Stock
+				if adj.Delta != nil {
+					newStock += *adj.Delta
+				} else {
+					newStock = *adj.Stock
+				}
+				result.Name = product.Name
+				result.OldStock, result.NewStock = &oldStock, &newStock
+
+				if newStock < 0 {
+					fail("error", "Stock would become negative")
+				} else if err := tx.Model(&product).
+					Updates(map[string]interface{}{"stock": newStock, "version": gorm.Expr("version + 1")}).Error; err != nil {
+					return err
+				} else if err := emitProductEvent(tx, shopID, webhooks.EventProductUpdated, product.ID); err != nil {
+					return err
+				}
+			}
+			seen[adj.ProductID] = true
+			results = append(results, result)
+		}
+
+		if failed {
+			return errBulkFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkFailed) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust stock"})
+		return
+	}
+
+	if failed {
+		// Rolled back: the adjustments that were valid have not been applied either
+		for i := range results {
+			if results[i].Status == "updated" {
+				results[i].Status = "skipped"
+				results[i].Error = "Not applied because another adjustment failed"
+			}
+		}
+		c.JSON(http.StatusUnprocessableEntity, newBulkResponse(results))
+		return
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(results))
+	for _, r := range results {
+		productIDs = append(productIDs, r.ProductID)
+	}
+	h.alerts.Check(shopID, productIDs...)
+	publishStock(h.db, h.hub, shopID, productIDs...)
+
+	c.JSON(http.StatusOK, newBulkResponse(results))
+}
+
+// Delete - soft deletes the given products; unknown IDs are reported, the others are deleted
+func (h *BulkProductHandler) Delete(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.BulkIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var products []models.Product
+	if err := h.db.Where("id IN ? AND shop_id = ?", req.IDs, shopID).Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return
+	}
+
+	found := map[uuid.UUID]models.Product{}
+	ids := make([]uuid.UUID, 0, len(products))
+	for _, p := range products {
+		found[p.ID] = p
+		ids = append(ids, p.ID)
+	}
+	if len(ids) > 0 {
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			// CRITICAL: Always include shopID in delete query
+			if err := tx.Where("id IN ? AND shop_id = ?", ids, shopID).Delete(&models.Product{}).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := emitProductEvent(tx, shopID, webhooks.EventProductDeleted, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete products"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, newBulkResponse(bulkIDResults(req.IDs, found, "deleted")))
+}
+
+// Restore - brings back soft-deleted products
+// A product whose SKU has been reused in the meantime cannot be restored until one of them changes
+func (h *BulkProductHandler) Restore(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.BulkIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var products []models.Product
+	if err := h.db.Unscoped().
+		Where("id IN ? AND shop_id = ? AND deleted_at IS NOT NULL", req.IDs, shopID).
+		Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return
+	}
+
+	found := map[uuid.UUID]models.Product{}
+	for _, p := range products {
+		found[p.ID] = p
+	}
+	results := bulkIDResults(req.IDs, found, "restored")
+
+	for i, r := range results {
+		if r.Status != "restored" {
+			continue
+		}
+		if err := restoreProduct(h.db, shopID, found[r.ProductID]); err != nil {
+			results[i].Status = "error"
+			results[i].Error = err.Error()
+		}
+	}
+
+	c.JSON(http.StatusOK, newBulkResponse(results))
+}
+
+// restoreProduct clears deleted_at, refusing when the SKU now belongs to a live product
+func restoreProduct(db *gorm.DB, shopID uuid.UUID, p models.Product) error {
+	if p.SKU != "" && skuTaken(db, shopID, p.SKU, p.ID) {
+		return errors.New("SKU " + p.SKU + " is used by another product")
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Product{}).
+			Where("id = ? AND shop_id = ?", p.ID, shopID).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		// Back in the catalog: receivers see it as an update of the product they deleted
+		return emitProductEvent(tx, shopID, webhooks.EventProductUpdated, p.ID)
+	})
+	if isUniqueViolation(err) {
+		return errors.New("SKU " + p.SKU + " is used by another product")
+	}
+	if err != nil {
+		return errors.New("Failed to restore product")
+	}
+	return nil
+}
+
+// bulkIDResults reports each requested ID once, in request order
+func bulkIDResults(ids []uuid.UUID, found map[uuid.UUID]models.Product, status string) []dto.BulkResult {
+	var results []dto.BulkResult
+	seen := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		p, ok := found[id]
+		if !ok {
+			results = append(results, dto.BulkResult{ProductID: id, Status: "not_found", Error: "Product not found"})
+			continue
+		}
+		results = append(results, dto.BulkResult{ProductID: id, Name: p.Name, Status: status})
+	}
+	return results
+}
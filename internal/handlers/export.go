@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"reflect"
+	"time"
+
+	"electronic-shop/internal/export"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/receipt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ExportHandler struct {
+	db *gorm.DB
+}
+
+func NewExportHandler(db *gorm.DB) *ExportHandler {
+	return &ExportHandler{db: db}
+}
+
+// startExport validates ?format= and sends the download headers
+// Nothing is written to the body yet, so errors before the first row can still be JSON
+func startExport(c *gin.Context, name string) (export.Writer, bool) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+	w, contentType, err := export.New(format, c.Writer, name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	filename := name + "-" + time.Now().Format("2006-01-02") + "." + format
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	return w, true
+}
+
+// streamRows writes every row of a query, flushing to the client as it goes
+// Once streaming started the status is already sent: failures are only recorded on the context
+func (h *ExportHandler) streamRows(c *gin.Context, w export.Writer, rows *sql.Rows, dest interface{}, toCells func() []export.Cell) {
+	defer rows.Close()
+
+	for n := 1; rows.Next(); n++ {
+		// Reset so NULL columns do not keep the previous row's value
+		reflect.ValueOf(dest).Elem().SetZero()
+		if err := h.db.ScanRows(rows, dest); err != nil {
+			_ = c.Error(err)
+			break
+		}
+		if err := w.WriteRow(toCells()...); err != nil {
+			_ = c.Error(err) // Client went away
+			return
+		}
+		if n%500 == 0 {
+			c.Writer.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = c.Error(err)
+	}
+	if err := w.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// ExportTransactions - streams the shop's transactions as CSV or XLSX
+// Honors the same filters as GetTransactions: type, date_from, date_to
+func (h *ExportHandler) ExportTransactions(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Deleted products keep their name in the export
+	query := h.db.Table("transactions AS t").
+		Select(`t.created_at, t.invoice_number, t.type, COALESCE(p.name, t.product_name) AS product_name, t.quantity,
+			t.unit_price, t.discount, t.discount_reason, t.net_amount, t.tax_rate, t.tax_amount, t.amount,
+			(SELECT string_agg(pm.method, '+' ORDER BY pm.method) FROM payments pm WHERE pm.transaction_id = t.id) AS payment_methods,
+			t.comment`).
+		Joins("LEFT JOIN products p ON p.id = t.product_id").
+		Where("t.shop_id = ?", shopID)
+
+	if transactionType := c.Query("type"); transactionType != "" {
+		query = query.Where("t.type = ?", transactionType)
+	}
+	query = applyDateRange(query, "t.created_at", c.Query("date_from"), c.Query("date_to"))
+
+	rows, err := query.Order("t.created_at DESC").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export transactions"})
+		return
+	}
+
+	w, ok := startExport(c, "transactions")
+	if !ok {
+		rows.Close()
+		return
+	}
+
+	if err := w.WriteRow(export.Header(
+		"date", "invoice_number", "type", "product", "quantity", "unit_price", "discount", "discount_reason",
+		"net_amount", "tax_rate", "tax_amount", "amount", "payment_methods", "comment",
+	)...); err != nil {
+		rows.Close()
+		return
+	}
+
+	var row struct {
+		CreatedAt      time.Time
+		InvoiceNumber  *int64
+		Type           string
+		ProductName    *string
+		Quantity       int
+		UnitPrice      money.Amount
+		Discount       money.Amount
+		DiscountReason string
+		NetAmount      money.Amount
+		TaxRate        float64
+		TaxAmount      money.Amount
+		Amount         money.Amount
+		PaymentMethods *string
+		Comment        string
+	}
+	h.streamRows(c, w, rows, &row, func() []export.Cell {
+		invoice, product, methods := "", "", ""
+		if row.InvoiceNumber != nil {
+			invoice = receipt.FormatInvoiceNumber(*row.InvoiceNumber)
+		}
+		if row.ProductName != nil {
+			product = *row.ProductName
+		}
+		if row.PaymentMethods != nil {
+			methods = *row.PaymentMethods
+		}
+		return []export.Cell{
+			export.Time(row.CreatedAt), export.Text(invoice), export.Text(row.Type), export.Text(product),
+			export.Int(int64(row.Quantity)), export.Money(row.UnitPrice), export.Money(row.Discount),
+			export.Text(row.DiscountReason), export.Money(row.NetAmount), export.Float(row.TaxRate),
+			export.Money(row.TaxAmount), export.Money(row.Amount), export.Text(methods), export.Text(row.Comment),
+		}
+	})
+}
+
+// ExportProducts - streams the product catalog as CSV or XLSX
+// Like toPrivateResponse, only SuperAdmin gets the purchase price column
+func (h *ExportHandler) ExportProducts(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	showPurchasePrice := middleware.GetRoleFromContext(c) == string(models.RoleSuperAdmin)
+
+	query := h.db.Model(&models.Product{}).Where("shop_id = ?", shopID)
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	rows, err := query.Order("name").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export products"})
+		return
+	}
+
+	w, ok := startExport(c, "products")
+	if !ok {
+		rows.Close()
+		return
+	}
+
+	header := []string{"id", "sku", "name", "category", "description", "selling_price"}
+	if showPurchasePrice {
+		header = append(header, "purchase_price")
+	}
+	header = append(header, "stock", "tax_class", "created_at")
+	if err := w.WriteRow(export.Header(header...)...); err != nil {
+		rows.Close()
+		return
+	}
+
+	var p models.Product
+	h.streamRows(c, w, rows, &p, func() []export.Cell {
+		cells := []export.Cell{
+			export.Text(p.ID.String()), export.Text(p.SKU), export.Text(p.Name), export.Text(p.Category),
+			export.Text(p.Description), export.Money(p.SellingPrice),
+		}
+		if showPurchasePrice {
+			cells = append(cells, export.Money(p.PurchasePrice))
+		}
+		return append(cells, export.Int(int64(p.Stock)), export.Text(taxClassOf(p)), export.Time(p.CreatedAt))
+	})
+}
+
+// ExportReport - streams a per-day summary of the period (SuperAdmin only)
+// Supports date_from / date_to (YYYY-MM-DD) like GetTransactions
+func (h *ExportHandler) ExportReport(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query := h.db.Model(&models.Transaction{}).
+		Select(`TO_CHAR(created_at, 'YYYY-MM-DD') AS day,
+			COALESCE(SUM(amount) FILTER (WHERE type = 'Sale'), 0)::bigint AS sales,
+			COALESCE(SUM(tax_amount) FILTER (WHERE type = 'Sale'), 0)::bigint AS tax,
+			COALESCE(SUM(amount) FILTER (WHERE type = 'Expense'), 0)::bigint AS expenses,
+			COALESCE(SUM(amount) FILTER (WHERE type = 'Withdrawal'), 0)::bigint AS withdrawals,
+			COALESCE(SUM(quantity) FILTER (WHERE type = 'Sale'), 0) AS items_sold,
+			COUNT(*) AS transactions`).
+		Where("shop_id = ?", shopID)
+	query = applyDateRange(query, "created_at", c.Query("date_from"), c.Query("date_to"))
+
+	rows, err := query.Group("day").Order("day").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export report"})
+		return
+	}
+
+	w, ok := startExport(c, "report")
+	if !ok {
+		rows.Close()
+		return
+	}
+
+	if err := w.WriteRow(export.Header(
+		"date", "sales", "tax", "expenses", "withdrawals", "net_profit", "items_sold", "transactions",
+	)...); err != nil {
+		rows.Close()
+		return
+	}
+
+	var row struct {
+		Day          string
+		Sales        money.Amount
+		Tax          money.Amount
+		Expenses     money.Amount
+		Withdrawals  money.Amount
+		ItemsSold    int64
+		Transactions int64
+	}
+	h.streamRows(c, w, rows, &row, func() []export.Cell {
+		return []export.Cell{
+			export.Text(row.Day), export.Money(row.Sales), export.Money(row.Tax), export.Money(row.Expenses),
+			export.Money(row.Withdrawals), export.Money(row.Sales - row.Expenses - row.Withdrawals),
+			export.Int(row.ItemsSold), export.Int(row.Transactions),
+		}
+	})
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxProductImages is the gallery size limit of a product
+const maxProductImages = 10
+
+type GalleryHandler struct {
+	db *gorm.DB
+}
+
+func NewGalleryHandler(db *gorm.DB) *GalleryHandler {
+	return &GalleryHandler{db: db}
+}
+
+// validImageURL accepts an uploaded file (/uploads/...) or an absolute http(s) URL
+func validImageURL(raw string) bool {
+	if strings.HasPrefix(raw, "/uploads/") {
+		return !strings.Contains(raw, "..")
+	}
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// findGalleryProduct loads a live product of the shop from the :id parameter, answering the error itself
+func (h *GalleryHandler) findGalleryProduct(c *gin.Context, shopID uuid.UUID) (models.Product, bool) {
+	var product models.Product
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return product, false
+	}
+	// CRITICAL: Always filter by shopID from JWT to ensure isolation
+	if err := h.db.Where("id = ? AND shop_id = ?", productID, shopID).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return product, false
+	}
+	return product, true
+}
+
+// productImages returns the gallery of a product in display order
+func productImages(db *gorm.DB, shopID, productID uuid.UUID) ([]models.ProductImage, error) {
+	images := []models.ProductImage{}
+	err := db.Where("product_id = ? AND shop_id = ?", productID, shopID).
+		Order("position, created_at").Find(&images).Error
+	return images, err
+}
+
+// savePositions numbers the images 0..n-1 in slice order
+func savePositions(tx *gorm.DB, images []models.ProductImage) error {
+	for i := range images {
+		if images[i].Position == i {
+			continue
+		}
+		images[i].Position = i
+		if err := tx.Model(&models.ProductImage{}).Where("id = ?", images[i].ID).
+			Update("position", i).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetImages - the gallery of a product, in display order
+func (h *GalleryHandler) GetImages(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	product, ok := h.findGalleryProduct(c, shopID)
+	if !ok {
+		return
+	}
+
+	images, err := productImages(h.db, shopID, product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch images"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"images": images,
+		"total":  len(images),
+	})
+}
+
+// AddImage - adds an image to the gallery, at the given position or last
+// Upload the file with POST /api/upload/image first and send the returned URL
+func (h *GalleryHandler) AddImage(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	product, ok := h.findGalleryProduct(c, shopID)
+	if !ok {
+		return
+	}
+
+	var req dto.AddProductImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validImageURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an uploaded image (/uploads/...) or an http(s) URL"})
+		return
+	}
+
+	image := models.ProductImage{
+		ProductID: product.ID,
+		URL:       req.URL,
+		AltText:   req.AltText,
+		ShopID:    shopID, // Always from JWT
+	}
+	full := false
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the product so concurrent additions cannot exceed the limit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND shop_id = ?", product.ID, shopID).First(&models.Product{}).Error; err != nil {
+			return err
+		}
+		images, err := productImages(tx, shopID, product.ID)
+		if err != nil {
+			return err
+		}
+		if len(images) >= maxProductImages {
+			full = true
+			return nil
+		}
+
+		position := len(images)
+		if req.Position != nil {
+			position = min(*req.Position, len(images))
+		}
+		image.Position = position
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+		images = append(images[:position], append([]models.ProductImage{image}, images[position:]...)...)
+		return savePositions(tx, images)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add image"})
+		return
+	}
+	if full {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A product has at most %d gallery images", maxProductImages)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, image)
+}
+
+// ReorderImages - sets the gallery order; image_ids must list every image of the product once
+func (h *GalleryHandler) ReorderImages(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	product, ok := h.findGalleryProduct(c, shopID)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := productImages(h.db, shopID, product.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch images"})
+		return
+	}
+
+	byID := map[uuid.UUID]models.ProductImage{}
+	for _, img := range images {
+		byID[img.ID] = img
+	}
+	ordered := make([]models.ProductImage, 0, len(req.ImageIDs))
+	for _, id := range req.ImageIDs {
+		img, found := byID[id]
+		if !found {
+			break
+		}
+		delete(byID, id)
+		ordered = append(ordered, img)
+	}
+	if len(ordered) != len(images) || len(req.ImageIDs) != len(images) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids must list every image of the product once"})
+		return
+	}
+
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		return savePositions(tx, ordered)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder images"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"images": ordered,
+		"total":  len(ordered),
+	})
+}
+
+// DeleteImage - removes an image from the gallery; the uploaded file itself is kept
+func (h *GalleryHandler) DeleteImage(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	product, ok := h.findGalleryProduct(c, shopID)
+	if !ok {
+		return
+	}
+
+	imageID, err := uuid.Parse(c.Param("imageID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	deleted := false
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		// CRITICAL: Always include shopID in delete query
+		result := tx.Where("id = ? AND product_id = ? AND shop_id = ?", imageID, product.ID, shopID).
+			Delete(&models.ProductImage{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = true
+		images, err := productImages(tx, shopID, product.ID)
+		if err != nil {
+			return err
+		}
+		return savePositions(tx, images)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete image"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted successfully"})
+}
@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/events"
+	"electronic-shop/internal/importer"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/notify"
+	"electronic-shop/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxImportSize bounds the uploaded file, XLSX included
+const maxImportSize = 10 << 20
+
+// importColumns - the columns understood by ImportProducts, named like the product JSON fields
+// Other columns (id, created_at... from an export) are ignored and reported
+var importColumns = map[string]string{
+	"sku":            "SKU",
+	"name":           "Name",
+	"description":    "Description",
+	"category":       "Category",
+	"purchase_price": "PurchasePrice",
+	"selling_price":  "SellingPrice",
+	"stock":          "Stock",
+	"tax_class":      "TaxClass",
+	"image_url":      "ImageURL",
+}
+
+// errImportConflict aborts the import when a product changed after the file was validated
+var errImportConflict = errors.New("product changed during the import")
+
+type ImportHandler struct {
+	db     *gorm.DB
+	alerts *notify.StockAlerter
+	hub    events.Hub
+}
+
+func NewImportHandler(db *gorm.DB, alerts *notify.StockAlerter, hub events.Hub) *ImportHandler {
+	return &ImportHandler{db: db, alerts: alerts, hub: hub}
+}
+
+// importRow - a validated row and the product it will create or update
+type importRow struct {
+	line    int
+	req     dto.CreateProductRequest
+	product *models.Product // nil: create
+}
+
+// ImportProducts - creates or updates products from an uploaded CSV or XLSX file
+// Each row is matched by sku, then by name (only against a product without SKU)
+// Every row must pass CreateProductRequest validation; a single error rejects the whole file
+// With ?dry_run=true nothing is written and the per-row report is returned
+func (h *ImportHandler) ImportProducts(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV or XLSX file of at most 10 MB is required in the 'file' field"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	table, err := importer.Read(header.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := dto.ProductImportResponse{
+		DryRun:         dryRun,
+		TotalRows:      len(table.Rows),
+		IgnoredColumns: []string{},
+		Rows:           []dto.ProductImportRow{},
+		Errors:         []dto.ImportRowError{},
+	}
+	for _, col := range table.Header {
+		if _, known := importColumns[col]; !known {
+			resp.IgnoredColumns = append(resp.IgnoredColumns, col)
+		}
+	}
+	if table.Column("name") < 0 || table.Column("selling_price") < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The file must have 'name' and 'selling_price' columns"})
+		return
+	}
+
+	var existing []models.Product
+	if err := h.db.Where("shop_id = ?", shopID).Find(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return
+	}
+	bySKU := map[string]*models.Product{}
+	byName := map[string][]*models.Product{}
+	for i := range existing {
+		p := &existing[i]
+		if p.SKU != "" {
+			bySKU[p.SKU] = p
+		}
+		key := strings.ToLower(p.Name)
+		byName[key] = append(byName[key], p)
+	}
+
+	// The same product must not appear twice in a file: the second row would silently win
+	seen := map[string]int{}
+	var rows []importRow
+
+	for _, r := range table.Rows {
+		row, rowErrs := parseImportRow(table, r)
+
+		if len(rowErrs) == 0 {
+			row.product, rowErrs = matchImportRow(row, bySKU, byName)
+		}
+		if len(rowErrs) == 0 {
+			keys := []string{"name:" + strings.ToLower(row.req.Name)}
+			if row.product != nil {
+				keys = []string{"id:" + row.product.ID.String()}
+			}
+			if row.req.SKU != "" {
+				keys = append(keys, "sku:"+row.req.SKU)
+			}
+			for _, key := range keys {
+				if first, dup := seen[key]; dup {
+					rowErrs = append(rowErrs, dto.ImportRowError{Line: r.Line, Error: "Same product as line " + strconv.Itoa(first)})
+					break
+				}
+			}
+			if len(rowErrs) == 0 {
+				for _, key := range keys {
+					seen[key] = r.Line
+				}
+			}
+		}
+
+		if len(rowErrs) == 0 && row.product != nil {
+			// Only the columns present in the file change; the rest keeps the stored values
+			row.req = mergeImportRow(table, *row.product, row.req)
+			rowErrs = validateImportRow(r.Line, row.req)
+		}
+
+		result := dto.ProductImportRow{Line: r.Line, SKU: row.req.SKU, Name: row.req.Name}
+		switch {
+		case len(rowErrs) > 0:
+			result.Action = "error"
+			resp.Errors = append(resp.Errors, rowErrs...)
+		case row.product != nil:
+			result.Action = "update"
+			result.ProductID = &row.product.ID
+			resp.Updated++
+		default:
+			result.Action = "create"
+			resp.Created++
+		}
+		resp.Rows = append(resp.Rows, result)
+		rows = append(rows, row)
+	}
+
+	if len(resp.Errors) > 0 {
+		resp.Created, resp.Updated = 0, 0
+		c.JSON(http.StatusUnprocessableEntity, resp)
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	// All or nothing
+	var productIDs []uuid.UUID
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		for i, row := range rows {
+			if row.product == nil {
+				product := models.Product{ShopID: shopID}
+				applyImportRow(&product, row.req)
+				if err := tx.Create(&product).Error; err != nil {
+					return err
+				}
+				resp.Rows[i].ProductID = &product.ID
+				productIDs = append(productIDs, product.ID)
+				if err := emitProductEvent(tx, shopID, webhooks.EventProductCreated, product.ID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// The version check fails if the product changed since the file was validated (sale, edit...)
+			p := row.product
+			result := tx.Model(&models.Product{}).
+				Where("id = ? AND shop_id = ? AND version = ?", p.ID, shopID, p.Version).
+				Updates(importUpdates(table, row.req))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errImportConflict
+			}
+			productIDs = append(productIDs, p.ID)
+			if err := emitProductEvent(tx, shopID, webhooks.EventProductUpdated, p.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errImportConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Products changed during the import, please retry"})
+			return
+		}
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Products changed during the import (duplicate SKU), please retry"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import products"})
+		return
+	}
+
+	h.alerts.Check(shopID, productIDs...)
+	publishStock(h.db, h.hub, shopID, productIDs...)
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseImportRow converts the known cells of a row into a CreateProductRequest and validates it
+func parseImportRow(table importer.Table, r importer.Row) (importRow, []dto.ImportRowError) {
+	row := importRow{line: r.Line}
+	var errs []dto.ImportRowError
+
+	cell := func(col string) string {
+		if i := table.Column(col); i >= 0 {
+			return r.Cells[i]
+		}
+		return ""
+	}
+	amount := func(col string) money.Amount {
+		v := cell(col)
+		if v == "" {
+			return 0
+		}
+		a, err := parseImportAmount(v)
+		if err != nil {
+			errs = append(errs, dto.ImportRowError{Line: r.Line, Column: col, Error: "Invalid amount '" + v + "'"})
+		}
+		return a
+	}
+
+	row.req = dto.CreateProductRequest{
+		SKU:           cell("sku"),
+		Name:          cell("name"),
+		Description:   cell("description"),
+		Category:      cell("category"),
+		PurchasePrice: amount("purchase_price"),
+		SellingPrice:  amount("selling_price"),
+		TaxClass:      cell("tax_class"),
+		ImageURL:      cell("image_url"),
+	}
+	if v := cell("stock"); v != "" {
+		stock, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, dto.ImportRowError{Line: r.Line, Column: "stock", Error: "Invalid quantity '" + v + "'"})
+		}
+		row.req.Stock = stock
+	}
+	if len(errs) > 0 {
+		return row, errs
+	}
+
+	return row, validateImportRow(r.Line, row.req)
+}
+
+// validateImportRow applies the same binding rules as the JSON CreateProduct endpoint
+func validateImportRow(line int, req dto.CreateProductRequest) []dto.ImportRowError {
+	err := binding.Validator.ValidateStruct(&req)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []dto.ImportRowError{{Line: line, Error: err.Error()}}
+	}
+
+	var errs []dto.ImportRowError
+	for _, fe := range fieldErrs {
+		column := fe.Field()
+		for col, field := range importColumns {
+			if field == fe.Field() {
+				column = col
+			}
+		}
+		msg := "Failed on the '" + fe.Tag() + "' rule"
+		if fe.Param() != "" {
+			msg += " (" + fe.Param() + ")"
+		}
+		errs = append(errs, dto.ImportRowError{Line: line, Column: column, Error: msg})
+	}
+	return errs
+}
+
+// matchImportRow finds the product a row updates, or nil when it creates one
+func matchImportRow(row importRow, bySKU map[string]*models.Product, byName map[string][]*models.Product) (*models.Product, []dto.ImportRowError) {
+	if row.req.SKU != "" {
+		if p, ok := bySKU[row.req.SKU]; ok {
+			return p, nil
+		}
+	}
+
+	// Falling back on the name only makes sense for products not identified by a SKU yet
+	var candidates []*models.Product
+	for _, p := range byName[strings.ToLower(row.req.Name)] {
+		if p.SKU == "" {
+			candidates = append(candidates, p)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, []dto.ImportRowError{{Line: row.line, Column: "name", Error: "Several products have this name, add a sku column to tell them apart"}}
+	}
+}
+
+// mergeImportRow fills the columns missing from the file with the stored product values
+func mergeImportRow(table importer.Table, p models.Product, req dto.CreateProductRequest) dto.CreateProductRequest {
+	has := func(col string) bool { return table.Column(col) >= 0 }
+
+	if req.SKU == "" {
+		req.SKU = p.SKU
+	}
+	if !has("description") {
+		req.Description = p.Description
+	}
+	if !has("category") {
+		req.Category = p.Category
+	}
+	if !has("purchase_price") {
+		req.PurchasePrice = p.PurchasePrice
+	}
+	if !has("stock") {
+		req.Stock = p.Stock
+	}
+	if !has("tax_class") || req.TaxClass == "" {
+		req.TaxClass = p.TaxClass
+	}
+	if !has("image_url") {
+		req.ImageURL = p.ImageURL
+	}
+	return req
+}
+
+// importUpdates lists the columns an update writes: only those present in the file
+// Stock and purchase price are not rewritten from the product read before validation
+func importUpdates(table importer.Table, req dto.CreateProductRequest) map[string]interface{} {
+	updates := map[string]interface{}{
+		"name":          req.Name,
+		"selling_price": req.SellingPrice,
+		"version":       gorm.Expr("version + 1"),
+	}
+	has := func(col string) bool { return table.Column(col) >= 0 }
+
+	if has("sku") && req.SKU != "" {
+		updates["sku"] = req.SKU
+	}
+	if has("description") {
+		updates["description"] = req.Description
+	}
+	if has("category") {
+		updates["category"] = req.Category
+	}
+	if has("purchase_price") {
+		updates["purchase_price"] = req.PurchasePrice
+	}
+	if has("stock") {
+		updates["stock"] = req.Stock
+	}
+	if has("tax_class") && req.TaxClass != "" {
+		updates["tax_class"] = req.TaxClass
+	}
+	if has("image_url") {
+		updates["image_url"] = req.ImageURL
+	}
+	return updates
+}
+
+func applyImportRow(p *models.Product, req dto.CreateProductRequest) {
+	if req.TaxClass == "" {
+		req.TaxClass = models.DefaultTaxClass
+	}
+	p.SKU = req.SKU
+	p.Name = req.Name
+	p.Description = req.Description
+	p.Category = req.Category
+	p.PurchasePrice = req.PurchasePrice
+	p.SellingPrice = req.SellingPrice
+	p.Stock = req.Stock
+	p.TaxClass = req.TaxClass
+	p.ImageURL = req.ImageURL
+}
+
+// parseImportAmount accepts "1299.99" as well as spreadsheet French formatting like "1 299,99"
+func parseImportAmount(s string) (money.Amount, error) {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' || r == '\u202f' {
+			return -1
+		}
+		return r
+	}, s)
+	if !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return money.Parse(s)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/importer"
+)
+
+func TestImportUpdates(t *testing.T) {
+	req := dto.CreateProductRequest{
+		SKU: "ECR-27", Name: "Écran 27", Description: "IPS", Category: "Écrans",
+		PurchasePrice: 150000, SellingPrice: 199900, Stock: 4, TaxClass: "standard", ImageURL: "https://img.example/e.png",
+	}
+	tests := []struct {
+		header []string
+		want   []string
+	}{
+		// A price list leaves stock and purchase price alone
+		{[]string{"sku", "name", "selling_price"}, []string{"name", "selling_price", "sku", "version"}},
+		{[]string{"name", "selling_price", "stock"}, []string{"name", "selling_price", "stock", "version"}},
+		{
+			[]string{"sku", "name", "description", "category", "purchase_price", "selling_price", "stock", "tax_class", "image_url"},
+			[]string{"category", "description", "image_url", "name", "purchase_price", "selling_price", "sku", "stock", "tax_class", "version"},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for col := range importUpdates(importer.Table{Header: tt.header}, req) {
+			got = append(got, col)
+		}
+		slices.Sort(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("importUpdates(%v) writes %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
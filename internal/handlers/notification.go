@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/notify"
+	"electronic-shop/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var whatsAppTargetPattern = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+
+type NotificationHandler struct {
+	db     *gorm.DB
+	alerts *notify.StockAlerter
+}
+
+func NewNotificationHandler(db *gorm.DB, alerts *notify.StockAlerter) *NotificationHandler {
+	return &NotificationHandler{db: db, alerts: alerts}
+}
+
+// validateNotificationTarget checks the target matches its channel and returns it normalized
+func validateNotificationTarget(ctx context.Context, channel, target string) (string, error) {
+	target = strings.TrimSpace(target)
+	switch channel {
+	case notify.ChannelEmail:
+		addr, err := mail.ParseAddress(target)
+		if err != nil {
+			return "", errors.New("target must be an e-mail address")
+		}
+		return addr.Address, nil
+	case notify.ChannelWebhook:
+		if err := webhooks.CheckURL(ctx, target); err != nil {
+			return "", fmt.Errorf("target: %w", err)
+		}
+		return target, nil
+	case notify.ChannelWhatsApp:
+		target = strings.NewReplacer(" ", "", "-", "", ".", "").Replace(target)
+		if !whatsAppTargetPattern.MatchString(target) {
+			return "", errors.New("target must be a phone number in international format")
+		}
+		return target, nil
+	}
+	// Log channel: the target is only a label
+	return target, nil
+}
+
+// GetSubscriptions - lists the shop's stock alert subscriptions and the channels this server can deliver
+func (h *NotificationHandler) GetSubscriptions(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var subscriptions []models.NotificationSubscription
+	if err := h.db.Where("shop_id = ?", shopID).Order("created_at").Find(&subscriptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions":      subscriptions,
+		"available_channels": h.alerts.Channels(),
+	})
+}
+
+// CreateSubscription - subscribes a target to the shop's stock alerts
+func (h *NotificationHandler) CreateSubscription(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.CreateNotificationSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := validateNotificationTarget(c.Request.Context(), req.Channel, req.Target)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription := models.NotificationSubscription{
+		Channel: req.Channel,
+		Target:  target,
+		Enabled: req.Enabled == nil || *req.Enabled,
+		ShopID:  shopID, // Always from JWT
+	}
+	// Enabled has a database default: create it enabled, then switch it off if asked
+	if err := h.db.Create(&subscription).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subscription"})
+		return
+	}
+	if !subscription.Enabled {
+		h.db.Model(&subscription).Update("enabled", false)
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// UpdateSubscription - changes the target or enables/disables a subscription
+func (h *NotificationHandler) UpdateSubscription(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	var req dto.UpdateNotificationSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var subscription models.NotificationSubscription
+	if err := h.db.Where("id = ? AND shop_id = ?", subscriptionID, shopID).First(&subscription).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Target != nil {
+		target, err := validateNotificationTarget(c.Request.Context(), subscription.Channel, *req.Target)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["target"] = target
+		updates["last_error"] = ""
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
+
+	if len(updates) > 0 {
+		if err := h.db.Model(&subscription).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subscription"})
+			return
+		}
+	}
+
+	h.db.First(&subscription, "id = ?", subscriptionID)
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription - removes a subscription
+func (h *NotificationHandler) DeleteSubscription(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	// CRITICAL: Always include shopID in delete query
+	result := h.db.Where("id = ? AND shop_id = ?", subscriptionID, shopID).Delete(&models.NotificationSubscription{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete subscription"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Subscription deleted successfully"})
+}
+
+// TestSubscription - sends a sample alert right away, to check the target receives it
+func (h *NotificationHandler) TestSubscription(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	var subscription models.NotificationSubscription
+	if err := h.db.Where("id = ? AND shop_id = ?", subscriptionID, shopID).First(&subscription).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	alert := notify.Alert{
+		Event:        notify.EventStockLow,
+		ShopID:       shop.ID,
+		ShopName:     shop.Name,
+		ProductName:  "Produit de test",
+		Stock:        shop.DefaultReorderPoint,
+		ReorderPoint: shop.DefaultReorderPoint,
+		At:           time.Now(),
+	}
+	if err := h.alerts.Send(c.Request.Context(), subscription, alert); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Delivery failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test alert sent"})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Discount types accepted on sales
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// saleQuote - server-side price breakdown of a Sale
+type saleQuote struct {
+	UnitPrice         money.Amount
+	Subtotal          money.Amount // UnitPrice * Quantity
+	PromotionDiscount money.Amount // Part of Discount coming from a promotion
+	Discount          money.Amount // Promotion + line + order discounts
+	Total             money.Amount // Subtotal - Discount, on the shop's price basis (with or without tax)
+	TaxRate           float64
+	NetAmount         money.Amount // Excluding tax
+	TaxAmount         money.Amount
+	Amount            money.Amount // Including tax: what the customer pays
+	DiscountReason    string
+}
+
+// taxPolicy - how VAT applies to a product of a shop
+type taxPolicy struct {
+	Rate             float64 // In %, 0 when no rate is configured for the product's tax class
+	PricesIncludeTax bool    // Whether selling prices are entered tax-inclusive
+}
+
+// splitTax derives net, tax and gross amounts from a price on the shop's price basis
+// net + tax always equals gross exactly
+func splitTax(price money.Amount, policy taxPolicy) (net, tax, gross money.Amount) {
+	if policy.PricesIncludeTax {
+		gross = price
+		net = gross.RemoveTax(policy.Rate)
+		return net, gross - net, gross
+	}
+	net = price
+	tax = net.Percent(policy.Rate)
+	return net, tax, net + tax
+}
+
+// maxDiscountPercent returns the maximum total discount (in % of the subtotal) a role may grant
+// Configurable with MAX_DISCOUNT_SUPERADMIN / MAX_DISCOUNT_ADMIN
+func maxDiscountPercent(role string) float64 {
+	key, fallback := "MAX_DISCOUNT_ADMIN", 10.0
+	if role == string(models.RoleSuperAdmin) {
+		key, fallback = "MAX_DISCOUNT_SUPERADMIN", 100.0
+	}
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
+
+// discountValue computes the amount taken off base by a discount
+func discountValue(base money.Amount, d *dto.DiscountRequest) (money.Amount, error) {
+	if d == nil {
+		return 0, nil
+	}
+
+	var value money.Amount
+	switch d.Type {
+	case DiscountPercent:
+		if d.Value <= 0 || d.Value > 100 {
+			return 0, errors.New("percentage discount must be between 0 and 100")
+		}
+		value = base.Percent(d.Value)
+	case DiscountFixed:
+		if d.Amount <= 0 {
+			return 0, errors.New("fixed discount amount must be greater than 0")
+		}
+		value = d.Amount
+	default:
+		return 0, errors.New("discount type must be percent or fixed")
+	}
+
+	if value > base {
+		return 0, errors.New("discount cannot exceed the price")
+	}
+	return value, nil
+}
+
+// promotionDiscount computes what a promotion takes off a line of qty units at unitPrice
+func promotionDiscount(p models.Promotion, unitPrice money.Amount, qty int) money.Amount {
+	gross := unitPrice.Mul(qty)
+
+	var value money.Amount
+	switch p.Type {
+	case models.PromotionPercentOff:
+		value = gross.Percent(p.Value)
+	case models.PromotionFixedOff:
+		value = money.FromFloat(p.Value).Mul(qty) // Fixed amount off each unit
+	case models.PromotionBuyXGetY:
+		if p.BuyQuantity > 0 && p.GetQuantity > 0 {
+			groups := qty / (p.BuyQuantity + p.GetQuantity)
+			value = unitPrice.Mul(groups * p.GetQuantity)
+		}
+	}
+
+	return money.Min(value, gross)
+}
+
+// priceSale computes the amount of a Sale from the product price, never from the client
+// The promotion (if any) applies first, then the line discount, then the order discount
+// Only manual discounts count toward the per-role cap; tax is computed on the discounted price
+func priceSale(product models.Product, req dto.CreateTransactionRequest, role string, promo *models.Promotion, policy taxPolicy) (saleQuote, error) {
+	q := saleQuote{
+		UnitPrice: product.SellingPrice,
+		Subtotal:  product.SellingPrice.Mul(req.Quantity),
+		TaxRate:   policy.Rate,
+	}
+
+	if promo != nil {
+		q.PromotionDiscount = promotionDiscount(*promo, product.SellingPrice, req.Quantity)
+	}
+	base := q.Subtotal - q.PromotionDiscount
+
+	lineDiscount, err := discountValue(base, req.LineDiscount)
+	if err != nil {
+		return q, fmt.Errorf("line discount: %w", err)
+	}
+	orderDiscount, err := discountValue(base-lineDiscount, req.OrderDiscount)
+	if err != nil {
+		return q, fmt.Errorf("order discount: %w", err)
+	}
+
+	manualDiscount := lineDiscount + orderDiscount
+	q.Discount = q.PromotionDiscount + manualDiscount
+	q.Total = q.Subtotal - q.Discount
+	q.NetAmount, q.TaxAmount, q.Amount = splitTax(q.Total, policy)
+
+	var reasons []string
+	if q.PromotionDiscount > 0 {
+		reasons = append(reasons, "Promotion: "+promo.Name)
+	}
+	if req.LineDiscount != nil {
+		reasons = append(reasons, req.LineDiscount.Reason)
+	}
+	if req.OrderDiscount != nil {
+		reasons = append(reasons, req.OrderDiscount.Reason)
+	}
+	q.DiscountReason = strings.Join(reasons, " / ")
+
+	// Per-role cap on the total discount
+	if q.Subtotal > 0 {
+		maxPercent := maxDiscountPercent(role)
+		if float64(manualDiscount)*100 > maxPercent*float64(q.Subtotal) {
+			return q, fmt.Errorf("discount exceeds the maximum of %.2f%% allowed for role %s", maxPercent, role)
+		}
+	}
+
+	// Selling below cost requires an explicit SuperAdmin override
+	// Purchase prices are tax-exclusive, so compare with the net amount
+	cost := product.PurchasePrice.Mul(req.Quantity)
+	if q.NetAmount < cost {
+		if !req.PriceOverride {
+			return q, fmt.Errorf("sale price %s (excl. tax) is below purchase price %s", q.NetAmount, cost)
+		}
+		if role != string(models.RoleSuperAdmin) {
+			return q, errors.New("only SuperAdmin can sell below purchase price")
+		}
+	}
+
+	// The client amount is optional; if sent it must agree with the server price
+	if req.Amount > 0 && req.Amount != q.Amount {
+		return q, fmt.Errorf("amount %s does not match computed price %s", req.Amount, q.Amount)
+	}
+
+	return q, nil
+}
+
+// shopTaxRates returns the VAT rate of each tax class configured for a shop
+// A failed lookup is an error, not an empty map: prices would silently lose their VAT
+func shopTaxRates(db *gorm.DB, shopID uuid.UUID) (map[string]float64, error) {
+	var rates []models.TaxRate
+	if err := db.Where("shop_id = ?", shopID).Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	byClass := make(map[string]float64, len(rates))
+	for _, r := range rates {
+		byClass[r.Class] = r.Rate
+	}
+	return byClass, nil
+}
+
+// taxClassOf returns the tax class of a product, defaulting to the standard class
+func taxClassOf(p models.Product) string {
+	if p.TaxClass == "" {
+		return models.DefaultTaxClass
+	}
+	return p.TaxClass
+}
+
+// displayPrice converts a stored selling price to the basis the shop shows publicly
+func displayPrice(price money.Amount, rate float64, shop models.Shop) money.Amount {
+	switch {
+	case shop.PricesIncludeTax && !shop.DisplayTaxInclusive:
+		return price.RemoveTax(rate)
+	case !shop.PricesIncludeTax && shop.DisplayTaxInclusive:
+		return price + price.Percent(rate)
+	}
+	return price
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"electronic-shop/internal/dto"
+	"electronic-shop/internal/events"
 	"electronic-shop/internal/middleware"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/notify"
+	"electronic-shop/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,25 +19,32 @@ import (
 )
 
 type ProductHandler struct {
-	db *gorm.DB
+	db     *gorm.DB
+	alerts *notify.StockAlerter
+	hub    events.Hub
 }
 
-func NewProductHandler(db *gorm.DB) *ProductHandler {
-	return &ProductHandler{db: db}
+func NewProductHandler(db *gorm.DB, alerts *notify.StockAlerter, hub events.Hub) *ProductHandler {
+	return &ProductHandler{db: db, alerts: alerts, hub: hub}
 }
 
 // toPrivateResponse converts a product to a response DTO
 // SuperAdmin sees PurchasePrice, Admin does not
 func toPrivateResponse(p models.Product, role string) dto.PrivateProductResponse {
 	resp := dto.PrivateProductResponse{
-		ID:           p.ID,
-		Name:         p.Name,
-		Description:  p.Description,
-		Category:     p.Category,
-		SellingPrice: p.SellingPrice,
-		Stock:        p.Stock,
-		ImageURL:     p.ImageURL,
-		ShopID:       p.ShopID,
+		ID:              p.ID,
+		SKU:             p.SKU,
+		Name:            p.Name,
+		Description:     p.Description,
+		Category:        p.Category,
+		SellingPrice:    p.SellingPrice,
+		Stock:           p.Stock,
+		TaxClass:        taxClassOf(p),
+		ImageURL:        p.ImageURL,
+		ReorderPoint:    p.ReorderPoint,
+		ReorderQuantity: p.ReorderQuantity,
+		Version:         p.Version,
+		ShopID:          p.ShopID,
 	}
 	// Only SuperAdmin can see purchase price
 	if role == string(models.RoleSuperAdmin) {
@@ -40,6 +53,62 @@ func toPrivateResponse(p models.Product, role string) dto.PrivateProductResponse
 	return resp
 }
 
+// hidePurchasePrice clears the purchase price of a preloaded product unless the role may see it
+// For models embedding a product (reservations, transactions) that are returned as is
+func hidePurchasePrice(p *models.Product, role string) {
+	if p != nil && role != string(models.RoleSuperAdmin) {
+		p.PurchasePrice = 0
+	}
+}
+
+// emitProductEvent queues a product webhook event with the product as saved in tx
+// Webhooks are configured by the SuperAdmin, so the payload is the SuperAdmin view
+func emitProductEvent(tx *gorm.DB, shopID uuid.UUID, event string, productID uuid.UUID) error {
+	var product models.Product
+	if err := tx.Unscoped().First(&product, "id = ? AND shop_id = ?", productID, shopID).Error; err != nil {
+		return err
+	}
+	return webhooks.Enqueue(tx, shopID, event, toPrivateResponse(product, string(models.RoleSuperAdmin)))
+}
+
+// productETag renders the product version as a strong ETag, e.g. "7"
+func productETag(p models.Product) string {
+	return `"` + strconv.Itoa(p.Version) + `"`
+}
+
+// parseProductETag reads the version from an If-Match value, accepting weak ETags too
+func parseProductETag(etag string) (int, bool) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	v, err := strconv.Atoi(strings.Trim(etag, `"`))
+	return v, err == nil
+}
+
+// skuTaken reports whether another live product of the shop already uses this SKU
+func skuTaken(db *gorm.DB, shopID uuid.UUID, sku string, exceptID uuid.UUID) bool {
+	var count int64
+	db.Model(&models.Product{}).
+		Where("shop_id = ? AND sku = ? AND id <> ?", shopID, sku, exceptID).
+		Count(&count)
+	return count > 0
+}
+
+// isUniqueViolation detects a PostgreSQL unique constraint error (SQLSTATE 23505)
+func isUniqueViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == "23505"
+}
+
+// filterProducts applies the category and name search filters shared by listings and bulk operations
+func filterProducts(query *gorm.DB, category, search string) *gorm.DB {
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+	return query
+}
+
 // GetProducts - returns all products for the authenticated user's shop
 func (h *ProductHandler) GetProducts(c *gin.Context) {
 	shopID, ok := middleware.GetShopIDFromContext(c)
@@ -52,14 +121,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	category := c.Query("category")
 	search := c.Query("search")
 
-	query := h.db.Where("shop_id = ?", shopID)
-
-	if category != "" {
-		query = query.Where("category = ?", category)
-	}
-	if search != "" {
-		query = query.Where("name ILIKE ?", "%"+search+"%")
-	}
+	query := filterProducts(h.db.Where("shop_id = ?", shopID), category, search)
 
 	var products []models.Product
 	if err := query.Find(&products).Error; err != nil {
@@ -104,6 +166,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	}
 
 	role := middleware.GetRoleFromContext(c)
+	c.Header("ETag", productETag(product))
 	c.JSON(http.StatusOK, toPrivateResponse(product, role))
 }
 
@@ -120,28 +183,53 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.TaxClass == "" {
+		req.TaxClass = models.DefaultTaxClass
+	}
+	req.SKU = strings.TrimSpace(req.SKU)
+	if req.SKU != "" && skuTaken(h.db, shopID, req.SKU, uuid.Nil) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A product with this SKU already exists"})
+		return
+	}
 
 	product := models.Product{
-		Name:          req.Name,
-		Description:   req.Description,
-		Category:      req.Category,
-		PurchasePrice: req.PurchasePrice,
-		SellingPrice:  req.SellingPrice,
-		Stock:         req.Stock,
-		ImageURL:      req.ImageURL,
-		ShopID:        shopID, // Always use shopID from JWT
+		SKU:             req.SKU,
+		Name:            req.Name,
+		Description:     req.Description,
+		Category:        req.Category,
+		PurchasePrice:   req.PurchasePrice,
+		SellingPrice:    req.SellingPrice,
+		Stock:           req.Stock,
+		TaxClass:        req.TaxClass,
+		ImageURL:        req.ImageURL,
+		ReorderPoint:    req.ReorderPoint,
+		ReorderQuantity: req.ReorderQuantity,
+		ShopID:          shopID, // Always use shopID from JWT
 	}
 
-	if err := h.db.Create(&product).Error; err != nil {
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+		return emitProductEvent(tx, shopID, webhooks.EventProductCreated, product.ID)
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A product with this SKU already exists"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
 		return
 	}
 
 	role := middleware.GetRoleFromContext(c)
+	c.Header("ETag", productETag(product))
 	c.JSON(http.StatusCreated, toPrivateResponse(product, role))
 }
 
-// UpdateProduct - updates a product (must belong to user's shop)
+// UpdateProduct - partially updates a product (must belong to user's shop)
+// Only the fields present in the body change. With If-Match, the update only applies
+// if the product is still at that version, otherwise 409 and the current product are returned
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	shopID, ok := middleware.GetShopIDFromContext(c)
 	if !ok {
@@ -162,6 +250,16 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	expectedVersion := product.Version
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		v, ok := parseProductETag(ifMatch)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+			return
+		}
+		expectedVersion = v
+	}
+
 	var req dto.UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -170,36 +268,102 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 
 	// Build update map (only update provided fields)
 	updates := map[string]interface{}{}
-	if req.Name != "" {
-		updates["name"] = req.Name
+	if req.SKU != nil {
+		sku := strings.TrimSpace(*req.SKU)
+		if sku != "" && skuTaken(h.db, shopID, sku, product.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A product with this SKU already exists"})
+			return
+		}
+		updates["sku"] = sku
+	}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
 	}
-	if req.Description != "" {
-		updates["description"] = req.Description
+	if req.Category != nil {
+		updates["category"] = *req.Category
 	}
-	if req.Category != "" {
-		updates["category"] = req.Category
+	if req.PurchasePrice != nil {
+		updates["purchase_price"] = *req.PurchasePrice
 	}
-	if req.PurchasePrice > 0 {
-		updates["purchase_price"] = req.PurchasePrice
+	if req.SellingPrice != nil {
+		updates["selling_price"] = *req.SellingPrice
 	}
-	if req.SellingPrice > 0 {
-		updates["selling_price"] = req.SellingPrice
+	if req.Stock != nil {
+		updates["stock"] = *req.Stock
 	}
-	if req.Stock >= 0 {
-		updates["stock"] = req.Stock
+	if req.TaxClass != nil {
+		taxClass := *req.TaxClass
+		if taxClass == "" {
+			taxClass = models.DefaultTaxClass
+		}
+		updates["tax_class"] = taxClass
 	}
-	if req.ImageURL != "" {
-		updates["image_url"] = req.ImageURL
+	if req.ImageURL != nil {
+		updates["image_url"] = *req.ImageURL
+	}
+	if req.ReorderPoint != nil {
+		if *req.ReorderPoint < 0 {
+			updates["reorder_point"] = nil
+		} else {
+			updates["reorder_point"] = *req.ReorderPoint
+		}
+	}
+	if req.ReorderQuantity != nil {
+		updates["reorder_quantity"] = *req.ReorderQuantity
 	}
 
-	if err := h.db.Model(&product).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+	role := middleware.GetRoleFromContext(c)
+
+	if len(updates) > 0 {
+		updates["version"] = gorm.Expr("version + 1")
+
+		// The version condition makes the check and the write a single atomic statement
+		var result *gorm.DB
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			result = tx.Model(&models.Product{}).
+				Where("id = ? AND shop_id = ? AND version = ?", productID, shopID, expectedVersion).
+				Updates(updates)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			return emitProductEvent(tx, shopID, webhooks.EventProductUpdated, productID)
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "A product with this SKU already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+			return
+		}
+		if result.RowsAffected == 0 {
+			h.db.Unscoped().First(&product, "id = ?", productID)
+			c.Header("ETag", productETag(product))
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Product was modified by someone else, reload it and retry",
+				"product": toPrivateResponse(product, role),
+			})
+			return
+		}
+	} else if expectedVersion != product.Version {
+		c.Header("ETag", productETag(product))
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Product was modified by someone else, reload it and retry",
+			"product": toPrivateResponse(product, role),
+		})
 		return
 	}
 
 	// Reload updated product
 	h.db.First(&product, "id = ?", productID)
-	role := middleware.GetRoleFromContext(c)
+	if req.Stock != nil {
+		h.alerts.Check(shopID, productID)
+		publishStock(h.db, h.hub, shopID, productID)
+	}
+	c.Header("ETag", productETag(product))
 	c.JSON(http.StatusOK, toPrivateResponse(product, role))
 }
 
@@ -218,8 +382,15 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	}
 
 	// CRITICAL: Always include shopID in delete query
-	result := h.db.Where("id = ? AND shop_id = ?", productID, shopID).Delete(&models.Product{})
-	if result.Error != nil {
+	var result *gorm.DB
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		result = tx.Where("id = ? AND shop_id = ?", productID, shopID).Delete(&models.Product{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return emitProductEvent(tx, shopID, webhooks.EventProductDeleted, productID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
 		return
 	}
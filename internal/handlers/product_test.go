@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/events"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHidePurchasePrice(t *testing.T) {
+	tests := []struct {
+		role string
+		want money.Amount
+	}{
+		{string(models.RoleSuperAdmin), money.FromUnits(700)},
+		{string(models.RoleAdmin), 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		reservation := models.Reservation{Product: &models.Product{PurchasePrice: money.FromUnits(700)}}
+		hidePurchasePrice(reservation.Product, tt.role)
+		if reservation.Product.PurchasePrice != tt.want {
+			t.Errorf("role %q: purchase price = %v, want %v", tt.role, reservation.Product.PurchasePrice, tt.want)
+		}
+
+		body, err := json.Marshal(reservation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shown := strings.Contains(string(body), `"purchase_price"`); shown != (tt.want != 0) {
+			t.Errorf("role %q: purchase_price in JSON = %v, want %v", tt.role, shown, tt.want != 0)
+		}
+	}
+
+	hidePurchasePrice(nil, string(models.RoleAdmin)) // Reservation of a purged product
+
+	// Transactions embed their product too: the sale response and the list apply the same rule
+	t.Run("transactions", func(t *testing.T) {
+		db := openTestDB(t)
+		shop := createTestShop(t, db)
+		product := models.Product{Name: "iPhone 15", PurchasePrice: money.FromUnits(700), SellingPrice: money.FromUnits(1000), Stock: 5, ShopID: shop.ID}
+		if err := db.Create(&product).Error; err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+		body, err := json.Marshal(dto.CreateTransactionRequest{
+			Type:      string(models.TransactionSale),
+			ProductID: &product.ID,
+			Quantity:  1,
+			Payments:  []dto.PaymentRequest{{Method: string(models.PaymentCash), Amount: product.SellingPrice}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gin.SetMode(gin.TestMode)
+		h := NewTransactionHandler(db, nil, events.NewMemoryHub())
+		for _, tt := range []struct {
+			role  string
+			shown bool
+		}{
+			{string(models.RoleSuperAdmin), true},
+			{string(models.RoleAdmin), false},
+		} {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("shopID", shop.ID)
+				c.Set("role", tt.role)
+			})
+			r.POST("/api/transactions", h.CreateTransaction)
+			r.GET("/api/transactions", h.GetTransactions)
+
+			for _, req := range []*http.Request{
+				httptest.NewRequest(http.MethodPost, "/api/transactions", bytes.NewReader(body)),
+				httptest.NewRequest(http.MethodGet, "/api/transactions", nil),
+			} {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != http.StatusOK && w.Code != http.StatusCreated {
+					t.Fatalf("%s %s as %s: status %d: %s", req.Method, req.URL, tt.role, w.Code, w.Body)
+				}
+				if shown := strings.Contains(w.Body.String(), `"purchase_price"`); shown != tt.shown {
+					t.Errorf("%s %s as %s: purchase_price in JSON = %v, want %v", req.Method, req.URL, tt.role, shown, tt.shown)
+				}
+			}
+		}
+	})
+}
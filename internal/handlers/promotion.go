@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PromotionHandler struct {
+	db *gorm.DB
+}
+
+func NewPromotionHandler(db *gorm.DB) *PromotionHandler {
+	return &PromotionHandler{db: db}
+}
+
+// runningPromotions returns the promotions of a shop that are active at the given time
+// When automaticOnly is set, coupon promotions are left out
+func runningPromotions(db *gorm.DB, shopID uuid.UUID, at time.Time, automaticOnly bool) ([]models.Promotion, error) {
+	query := db.Where("shop_id = ? AND active = true", shopID).
+		Where("starts_at IS NULL OR starts_at <= ?", at).
+		Where("ends_at IS NULL OR ends_at >= ?", at).
+		Where("max_uses = 0 OR uses_count < max_uses")
+	if automaticOnly {
+		query = query.Where("coupon_code = ''")
+	}
+
+	var promotions []models.Promotion
+	err := query.Find(&promotions).Error
+	return promotions, err
+}
+
+// bestPromotion picks the promotion giving the largest discount on a line
+// Promotions never stack; a coupon only unlocks its own promotion
+func bestPromotion(promotions []models.Promotion, product models.Product, qty int, coupon string, at time.Time) *models.Promotion {
+	var best *models.Promotion
+	var bestValue money.Amount
+	for i := range promotions {
+		p := &promotions[i]
+		if p.CouponCode != "" && p.CouponCode != coupon {
+			continue
+		}
+		if !p.AppliesTo(product, at) {
+			continue
+		}
+		if v := promotionDiscount(*p, product.SellingPrice, qty); v > bestValue {
+			best, bestValue = p, v
+		}
+	}
+	return best
+}
+
+// couponApplies reports whether a running coupon promotion matches the code and the product
+func couponApplies(promotions []models.Promotion, product models.Product, coupon string, at time.Time) bool {
+	for _, p := range promotions {
+		if p.CouponCode == coupon && p.AppliesTo(product, at) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCoupon - coupon codes are case-insensitive and stored upper-case
+func normalizeCoupon(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validatePromotion checks the rule fields that depend on the promotion type
+func validatePromotion(req dto.PromotionRequest) error {
+	switch models.PromotionType(req.Type) {
+	case models.PromotionPercentOff:
+		if req.Value <= 0 || req.Value > 100 {
+			return errors.New("value must be between 0 and 100 for PercentOff")
+		}
+	case models.PromotionFixedOff:
+		if req.Value <= 0 {
+			return errors.New("value must be greater than 0 for FixedOff")
+		}
+	case models.PromotionBuyXGetY:
+		if req.BuyQuantity < 1 || req.GetQuantity < 1 {
+			return errors.New("buy_quantity and get_quantity must be at least 1 for BuyXGetY")
+		}
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// applyPromotionRequest copies a validated request onto a promotion
+func applyPromotionRequest(p *models.Promotion, req dto.PromotionRequest) {
+	p.Name = req.Name
+	p.Type = models.PromotionType(req.Type)
+	p.Value = 0
+	if p.Type == models.PromotionPercentOff || p.Type == models.PromotionFixedOff {
+		p.Value = req.Value
+	}
+	p.BuyQuantity = req.BuyQuantity
+	p.GetQuantity = req.GetQuantity
+	p.Category = req.Category
+	p.ProductID = req.ProductID
+	p.CouponCode = normalizeCoupon(req.CouponCode)
+	p.StartsAt = req.StartsAt
+	p.EndsAt = req.EndsAt
+	p.MaxUses = req.MaxUses
+	p.Active = req.Active == nil || *req.Active
+}
+
+// checkPromotionTargets makes sure the coupon is unique and the product belongs to the shop
+func (h *PromotionHandler) checkPromotionTargets(shopID uuid.UUID, p models.Promotion) (int, error) {
+	if p.ProductID != nil {
+		var count int64
+		h.db.Model(&models.Product{}).Where("id = ? AND shop_id = ?", *p.ProductID, shopID).Count(&count)
+		if count == 0 {
+			return http.StatusBadRequest, errors.New("product not found")
+		}
+	}
+	if p.CouponCode != "" {
+		var count int64
+		h.db.Model(&models.Promotion{}).
+			Where("shop_id = ? AND coupon_code = ? AND id <> ?", shopID, p.CouponCode, p.ID).
+			Count(&count)
+		if count > 0 {
+			return http.StatusConflict, errors.New("coupon code already in use")
+		}
+	}
+	return 0, nil
+}
+
+// GetPromotions - lists all promotions of the shop
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var promotions []models.Promotion
+	if err := h.db.Where("shop_id = ?", shopID).Order("created_at DESC").Find(&promotions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promotions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotions": promotions, "total": len(promotions)})
+}
+
+// CreatePromotion - creates a promotion or coupon (SuperAdmin only)
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validatePromotion(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promotion := models.Promotion{ShopID: shopID} // Always from JWT
+	applyPromotionRequest(&promotion, req)
+
+	if status, err := h.checkPromotionTargets(shopID, promotion); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.Create(&promotion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create promotion"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, promotion)
+}
+
+// UpdatePromotion - replaces the rules of a promotion (SuperAdmin only)
+// The usage counter is kept
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	promotionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return
+	}
+
+	var promotion models.Promotion
+	if err := h.db.Where("id = ? AND shop_id = ?", promotionID, shopID).First(&promotion).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		return
+	}
+
+	var req dto.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validatePromotion(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyPromotionRequest(&promotion, req)
+
+	if status, err := h.checkPromotionTargets(shopID, promotion); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A map writes zero values too (active=false, cleared category...)
+	// uses_count is left out: sales increment it concurrently
+	if err := h.db.Model(&promotion).Updates(map[string]interface{}{
+		"name":         promotion.Name,
+		"type":         promotion.Type,
+		"value":        promotion.Value,
+		"buy_quantity": promotion.BuyQuantity,
+		"get_quantity": promotion.GetQuantity,
+		"category":     promotion.Category,
+		"product_id":   promotion.ProductID,
+		"coupon_code":  promotion.CouponCode,
+		"starts_at":    promotion.StartsAt,
+		"ends_at":      promotion.EndsAt,
+		"max_uses":     promotion.MaxUses,
+		"active":       promotion.Active,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update promotion"})
+		return
+	}
+
+	h.db.First(&promotion, "id = ?", promotion.ID)
+	c.JSON(http.StatusOK, promotion)
+}
+
+// DeletePromotion - deletes a promotion (SuperAdmin only)
+// Past sales keep their promotion_id for history
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	promotionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return
+	}
+
+	result := h.db.Where("id = ? AND shop_id = ?", promotionID, shopID).Delete(&models.Promotion{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete promotion"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
@@ -1,12 +1,19 @@
 package handlers
 
 import (
-	"fmt"
+	"cmp"
+	"errors"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"electronic-shop/internal/dto"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/slug"
+	"electronic-shop/internal/whatsapp"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -22,121 +29,573 @@ func NewPublicHandler(db *gorm.DB) *PublicHandler {
 }
 
 // buildWhatsAppLink generates the formatted WhatsApp redirect URL
-func buildWhatsAppLink(whatsAppNumber, productName string) string {
-	message := fmt.Sprintf("Bonjour je veux plus d'information sur %s", productName)
-	encodedMessage := url.QueryEscape(message)
-	return fmt.Sprintf("https://wa.me/%s?text=%s", whatsAppNumber, encodedMessage)
+func buildWhatsAppLink(whatsAppNumber, message string) string {
+	return whatsapp.Link(whatsAppNumber, message)
 }
 
-// GetPublicProducts - returns products for a shop (no auth required)
-// SECURITY: Never exposes PurchasePrice
-func (h *PublicHandler) GetPublicProducts(c *gin.Context) {
-	shopIDStr := c.Param("shopID")
-	shopID, err := uuid.Parse(shopIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shop ID format"})
+// CheckPublicBaseURL validates PUBLIC_BASE_URL, the public address of the server
+// It is required: absolute URLs (link previews, product links in WhatsApp messages) must never
+// come from the request Host header, which any client can set
+func CheckPublicBaseURL() error {
+	raw := os.Getenv("PUBLIC_BASE_URL")
+	if raw == "" {
+		return errors.New("PUBLIC_BASE_URL is required, e.g. https://shop.example.com")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return errors.New("PUBLIC_BASE_URL must be an absolute http(s) URL without query, e.g. https://shop.example.com")
+	}
+	return nil
+}
+
+// publicURL makes a path absolute with PUBLIC_BASE_URL (checked at startup by CheckPublicBaseURL)
+func publicURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/") + path
+}
+
+// publicMessage - the WhatsApp message template chosen for the customer, and the base of product URLs
+type publicMessage struct {
+	language string // "" for whatsapp.DefaultTemplate
+	template string
+	baseURL  string
+}
+
+// customerMessage picks the shop's WhatsApp template in the customer's language (?lang, else Accept-Language)
+// Without one, the template of the shop's default language is used, then whatsapp.DefaultTemplate
+func customerMessage(c *gin.Context, db *gorm.DB, shop models.Shop) publicMessage {
+	msg := publicMessage{template: whatsapp.DefaultTemplate, baseURL: strings.TrimSuffix(publicURL("/"), "/")}
+
+	var templates []models.WhatsAppTemplate
+	db.Where("shop_id = ?", shop.ID).Order("language").Find(&templates)
+	if len(templates) == 0 {
+		return msg
+	}
+
+	languages := make([]string, len(templates))
+	for i, t := range templates {
+		languages[i] = t.Language
+	}
+	chosen := whatsapp.MatchLanguage(languages, c.Query("lang"), c.GetHeader("Accept-Language"))
+	if chosen == "" {
+		chosen = shop.DefaultLanguage
+	}
+	for _, t := range templates {
+		if t.Language == chosen {
+			msg.language, msg.template = t.Language, t.Body
+		}
+	}
+	return msg
+}
+
+// applyPublicPromotion sets the promotional price of a single unit when a promotion applies
+// Buy-X-get-Y promotions have no unit price, only their name is shown
+func applyPublicPromotion(resp *dto.PublicProductResponse, promotions []models.Promotion, p models.Product, at time.Time) {
+	promo := bestPromotion(promotions, p, 1, "", at)
+	if promo == nil {
+		// Quantity-based promotions give nothing on a single unit
+		for i := range promotions {
+			if promotions[i].Type == models.PromotionBuyXGetY && promotions[i].AppliesTo(p, at) {
+				resp.Promotion = promotions[i].Name
+				return
+			}
+		}
 		return
 	}
+	resp.Promotion = promo.Name
+	resp.PromotionalPrice = p.SellingPrice - promotionDiscount(*promo, p.SellingPrice, 1)
+}
 
-	// Verify shop exists and is active
-	var shop models.Shop
-	if err := h.db.Where("id = ? AND active = true", shopID).First(&shop).Error; err != nil {
+// publicCatalog - shop data the public price and stock of its products are computed from, loaded once per request
+type publicCatalog struct {
+	shop       models.Shop
+	promotions []models.Promotion
+	taxRates   map[string]float64
+	reserved   map[uuid.UUID]int
+	message    publicMessage
+	routes     []whatsapp.Route
+	now        time.Time // In the shop's time zone, for the routes' opening hours
+}
+
+func loadPublicCatalog(db *gorm.DB, shop models.Shop, message publicMessage) (publicCatalog, error) {
+	now := time.Now()
+	// Automatic promotions running now (coupons are never advertised)
+	promotions, _ := runningPromotions(db, shop.ID, now, true)
+	// Displayed prices include VAT: without the rates they would be wrong
+	taxRates, err := shopTaxRates(db, shop.ID)
+	if err != nil {
+		return publicCatalog{}, err
+	}
+	// Held units are not offered: without them reserved stock would be sold twice
+	reserved, err := reservedByProduct(db, shop.ID)
+	if err != nil {
+		return publicCatalog{}, err
+	}
+	return publicCatalog{
+		shop:       shop,
+		promotions: promotions,
+		taxRates:   taxRates,
+		reserved:   reserved,
+		message:    message,
+		routes:     whatsAppRoutes(db, shop.ID),
+		now:        now.In(shopLocation(shop)),
+	}, nil
+}
+
+// shopLocation - time zone of the shop's opening hours
+func shopLocation(shop models.Shop) *time.Location {
+	loc, err := time.LoadLocation(cmp.Or(shop.Timezone, whatsapp.DefaultTimezone))
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// whatsAppRoutes loads the shop's WhatsApp routes with their numbers, in the order they are tried
+func whatsAppRoutes(db *gorm.DB, shopID uuid.UUID) []whatsapp.Route {
+	var rows []models.WhatsAppRoute
+	db.Preload("Contact").Where("shop_id = ?", shopID).Order("position").Find(&rows)
+
+	routes := make([]whatsapp.Route, 0, len(rows))
+	for _, r := range rows {
+		if r.Contact == nil || r.Contact.ShopID != shopID {
+			continue
+		}
+		routes = append(routes, whatsapp.Route{
+			Label:    r.Contact.Label,
+			Number:   r.Contact.Number,
+			Category: r.Category,
+			Schedule: whatsapp.Schedule{Days: r.Days, Opens: r.Opens, Closes: r.Closes},
+		})
+	}
+	return routes
+}
+
+// response builds the public view of a product - NEVER include PurchasePrice
+func (pc publicCatalog) response(p models.Product) dto.PublicProductResponse {
+	// Customers see what they can still buy: units held by reservations are not offered
+	available := p.Stock - pc.reserved[p.ID]
+	if available < 0 {
+		available = 0
+	}
+
+	stockStatus := "En stock"
+	if available == 0 {
+		stockStatus = "Rupture de stock"
+	} else if available <= p.EffectiveReorderPoint(pc.shop.DefaultReorderPoint) {
+		stockStatus = "Stock limité"
+	}
+
+	taxRate := pc.taxRates[taxClassOf(p)]
+	resp := dto.PublicProductResponse{
+		ID:               p.ID,
+		Name:             p.Name,
+		Description:      p.Description,
+		Category:         p.Category,
+		SellingPrice:     displayPrice(p.SellingPrice, taxRate, pc.shop),
+		PriceIncludesTax: pc.shop.DisplayTaxInclusive,
+		TaxRate:          taxRate,
+		Stock:            available,
+		StockStatus:      stockStatus,
+		ImageURL:         p.ImageURL,
+	}
+	applyPublicPromotion(&resp, pc.promotions, p, pc.now)
+	if resp.PromotionalPrice > 0 {
+		resp.PromotionalPrice = displayPrice(resp.PromotionalPrice, taxRate, pc.shop)
+	}
+	// The department of the product answers while open, else the shop's main number
+	number := pc.shop.WhatsAppNumber
+	if route, ok := whatsapp.Pick(pc.routes, p.Category, pc.now); ok {
+		number, resp.WhatsAppContact = route.Number, route.Label
+	}
+	resp.WhatsAppLink = buildWhatsAppLink(number, pc.whatsAppMessage(p, resp))
+	resp.WhatsAppRedirect = pc.message.baseURL + whatsAppRedirectPath(pc.shop, p.ID, pc.message.language)
+	return resp
+}
+
+// whatsAppMessage fills the customer's message template for a product, at the price they see
+func (pc publicCatalog) whatsAppMessage(p models.Product, resp dto.PublicProductResponse) string {
+	price := resp.SellingPrice
+	if resp.PromotionalPrice > 0 {
+		price = resp.PromotionalPrice
+	}
+	return whatsapp.Render(pc.message.template, whatsapp.Vars{
+		ProductName: p.Name,
+		Price:       money.Format(price, pc.shop.Currency),
+		ProductURL:  pc.message.baseURL + productPath(pc.shop, p.ID),
+		SKU:         p.SKU,
+		ShopName:    pc.shop.Name,
+	})
+}
+
+// lookupPublicShop finds an active shop by UUID or by slug
+// A former slug finds the shop too, with moved set: the caller redirects to the current slug
+func lookupPublicShop(db *gorm.DB, key string) (shop models.Shop, moved bool, err error) {
+	key = strings.ToLower(key)
+	if shopID, err := uuid.Parse(key); err == nil {
+		return shop, false, db.Where("id = ? AND active = true", shopID).First(&shop).Error
+	}
+	if slug.Validate(key) != nil {
+		return shop, false, gorm.ErrRecordNotFound
+	}
+
+	err = db.Where("slug = ? AND active = true", key).First(&shop).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return shop, false, err
+	}
+	err = db.Where("active = true AND id = (SELECT shop_id FROM shop_slug_redirects WHERE slug = ?)", key).First(&shop).Error
+	return shop, err == nil, err
+}
+
+// redirectToSlug answers 301 to the same URL with the shop segment replaced by the current slug
+// Other segments are kept escaped as they came (a category may contain "/")
+func redirectToSlug(c *gin.Context, former, current string) {
+	segments := strings.Split(c.Request.URL.EscapedPath(), "/")
+	for i, s := range segments {
+		if s == url.PathEscape(former) {
+			segments[i] = current
+			break
+		}
+	}
+	target := strings.Join(segments, "/")
+	if c.Request.URL.RawQuery != "" {
+		target += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, target)
+}
+
+// findPublicShop loads the active shop of the :shopID parameter (UUID or slug), answering the error itself
+// Former slugs are redirected to the current one
+func (h *PublicHandler) findPublicShop(c *gin.Context) (models.Shop, bool) {
+	key := c.Param("shopID")
+	shop, moved, err := lookupPublicShop(h.db, key)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found or inactive"})
-		return
+		return shop, false
 	}
+	if moved {
+		redirectToSlug(c, key, shop.Slug)
+		return shop, false
+	}
+	return shop, true
+}
 
-	// Optional: filter by category
-	category := c.Query("category")
-	hideOutOfStock := c.Query("in_stock_only") == "true"
+// publicShopInfo - the shop as shown alongside public products
+func publicShopInfo(shop models.Shop) gin.H {
+	return gin.H{
+		"id":       shop.ID,
+		"slug":     shop.Slug,
+		"name":     shop.Name,
+		"currency": shop.Currency,
+	}
+}
+
+// publicQueryError - a catalog query parameter that cannot be used, answered with 400
+type publicQueryError string
+
+func (e publicQueryError) Error() string { return string(e) }
+
+// publicProductPage - one page of the public catalog; PerPage is 0 when the whole list is returned
+type publicProductPage struct {
+	Products []dto.PublicProductResponse
+	Total    int64 // All matching products, across pages
+	Page     int
+	PerPage  int
+}
 
-	query := h.db.Where("shop_id = ?", shopID)
-	if category != "" {
-		query = query.Where("category = ?", category)
+// listPublicProducts searches, filters, sorts and paginates the public catalog of a shop
+func listPublicProducts(db *gorm.DB, shop models.Shop, params dto.PublicProductQuery, message publicMessage) (publicProductPage, error) {
+	var result publicProductPage
+
+	query := db.Model(&models.Product{}).Where("products.shop_id = ?", shop.ID)
+	if params.Category != "" {
+		query = query.Where("products.category = ?", params.Category)
+	}
+	if params.InStockOnly {
+		query = query.Where("products.stock > " + heldStockSQL)
+	}
+
+	tsQuery := searchTSQuery(params.Q)
+	if tsQuery != "" {
+		query = query.Where(models.ProductSearchVector+" @@ to_tsquery('french_unaccent', ?)", tsQuery)
+	}
+
+	query, priceSQL := withDisplayPrice(query, shop)
+	for _, bound := range []struct{ param, op, value string }{
+		{"min_price", ">=", params.MinPrice},
+		{"max_price", "<=", params.MaxPrice},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		price, err := money.Parse(bound.value)
+		if err != nil || price < 0 {
+			return result, publicQueryError("Invalid " + bound.param)
+		}
+		query = query.Where(priceSQL+" "+bound.op+" ?", price)
 	}
-	if hideOutOfStock {
-		query = query.Where("stock > 0")
+
+	query = query.Session(&gorm.Session{})
+	if err := query.Count(&result.Total).Error; err != nil {
+		return result, err
+	}
+
+	sort := params.Sort
+	if sort == "" || (sort == "relevance" && tsQuery == "") {
+		sort = "name"
+		if tsQuery != "" {
+			sort = "relevance"
+		}
+	}
+	query = query.Select("products.*")
+	switch sort {
+	case "relevance":
+		query = query.Select("products.*, ts_rank("+models.ProductSearchVector+", to_tsquery('french_unaccent', ?)) AS search_rank", tsQuery).
+			Order("search_rank DESC")
+	case "price_asc":
+		query = query.Order(priceSQL)
+	case "price_desc":
+		query = query.Order(priceSQL + " DESC")
+	case "newest":
+		query = query.Order("products.created_at DESC")
+	case "popularity":
+		// Units sold over the shop's recent sales window
+		since := time.Now().AddDate(0, 0, -max(shop.SalesVelocityDays, 1))
+		query = query.Joins(`LEFT JOIN (SELECT product_id, SUM(quantity) AS sold FROM transactions
+			WHERE shop_id = ? AND type = ? AND created_at >= ? GROUP BY product_id) sales
+			ON sales.product_id = products.id`, shop.ID, models.TransactionSale, since).
+			Order("COALESCE(sales.sold, 0) DESC")
+	}
+	// Stable order for pagination
+	query = query.Order("products.name").Order("products.id")
+
+	if params.Page > 0 || params.PerPage > 0 {
+		result.Page, result.PerPage = max(params.Page, 1), cmp.Or(params.PerPage, publicPerPage)
+		query = query.Offset((result.Page - 1) * result.PerPage).Limit(result.PerPage)
 	}
 
 	var products []models.Product
 	if err := query.Find(&products).Error; err != nil {
+		return result, err
+	}
+
+	catalog, err := loadPublicCatalog(db, shop, message)
+	if err != nil {
+		return result, err
+	}
+	result.Products = []dto.PublicProductResponse{}
+	for _, p := range products {
+		result.Products = append(result.Products, catalog.response(p))
+	}
+	return result, nil
+}
+
+// TotalPages - number of pages of the result, 1 when it is not paginated
+func (r publicProductPage) TotalPages() int {
+	if r.PerPage == 0 {
+		return 1
+	}
+	return int((r.Total + int64(r.PerPage) - 1) / int64(r.PerPage))
+}
+
+// GetPublicProducts - returns products for a shop (no auth required)
+// Full-text search, price filters, sorting and optional pagination: see dto.PublicProductQuery
+// SECURITY: Never exposes PurchasePrice
+func (h *PublicHandler) GetPublicProducts(c *gin.Context) {
+	// Verify shop exists and is active
+	shop, ok := h.findPublicShop(c)
+	if !ok {
+		return
+	}
+
+	var params dto.PublicProductQuery
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := listPublicProducts(h.db, shop, params, customerMessage(c, h.db, shop))
+	var queryErr publicQueryError
+	if errors.As(err, &queryErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": queryErr.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
 		return
 	}
 
-	// Build public response - NEVER include PurchasePrice
-	var responses []dto.PublicProductResponse
-	for _, p := range products {
-		stockStatus := "En stock"
-		if p.Stock == 0 {
-			stockStatus = "Rupture de stock"
-		} else if p.Stock < 5 {
-			stockStatus = "Stock limité"
+	resp := gin.H{
+		"shop":     publicShopInfo(shop),
+		"products": result.Products,
+		"total":    result.Total,
+	}
+	if result.PerPage > 0 {
+		resp["page"] = result.Page
+		resp["per_page"] = result.PerPage
+		resp["total_pages"] = result.TotalPages()
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// loadPublicProduct returns a product of the shop with its gallery and related products of the same category
+func loadPublicProduct(db *gorm.DB, shop models.Shop, productID uuid.UUID, message publicMessage) (dto.PublicProductDetailResponse, error) {
+	var resp dto.PublicProductDetailResponse
+
+	// Product must belong to this shop (multi-tenant)
+	var product models.Product
+	if err := db.Where("id = ? AND shop_id = ?", productID, shop.ID).First(&product).Error; err != nil {
+		return resp, err
+	}
+
+	var images []models.ProductImage
+	if err := db.Where("product_id = ? AND shop_id = ?", product.ID, shop.ID).
+		Order("position, created_at").Find(&images).Error; err != nil {
+		return resp, err
+	}
+
+	var related []models.Product
+	if product.Category != "" {
+		if err := db.Where("products.shop_id = ? AND products.category = ? AND products.id <> ?", shop.ID, product.Category, product.ID).
+			Order("products.stock > " + heldStockSQL + " DESC").
+			Order("products.name").
+			Limit(publicRelatedLimit).
+			Find(&related).Error; err != nil {
+			return resp, err
 		}
+	}
 
-		responses = append(responses, dto.PublicProductResponse{
-			ID:           p.ID,
-			Name:         p.Name,
-			Description:  p.Description,
-			Category:     p.Category,
-			SellingPrice: p.SellingPrice,
-			Stock:        p.Stock,
-			StockStatus:  stockStatus,
-			ImageURL:     p.ImageURL,
-			WhatsAppLink: buildWhatsAppLink(shop.WhatsAppNumber, p.Name),
-		})
+	catalog, err := loadPublicCatalog(db, shop, message)
+	if err != nil {
+		return resp, err
+	}
+	resp = dto.PublicProductDetailResponse{
+		PublicProductResponse: catalog.response(product),
+		Images:                []dto.PublicProductImage{},
+		Related:               []dto.PublicProductResponse{},
+	}
+	for _, img := range images {
+		resp.Images = append(resp.Images, dto.PublicProductImage{URL: img.URL, AltText: img.AltText})
+	}
+	for _, p := range related {
+		resp.Related = append(resp.Related, catalog.response(p))
+	}
+	return resp, nil
+}
+
+// GetPublicProduct - product page: the product, its gallery images and related products of the same category
+// SECURITY: Never exposes PurchasePrice
+func (h *PublicHandler) GetPublicProduct(c *gin.Context) {
+	shop, ok := h.findPublicShop(c)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("productID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
 	}
 
-	if responses == nil {
-		responses = []dto.PublicProductResponse{}
+	product, err := loadPublicProduct(h.db, shop, productID, customerMessage(c, h.db, shop))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"shop": gin.H{
-			"id":   shop.ID,
-			"name": shop.Name,
-		},
-		"products": responses,
-		"total":    len(responses),
+		"shop":    publicShopInfo(shop),
+		"product": product,
 	})
 }
 
-// GetWhatsAppLink - returns the WhatsApp redirect link for a specific product
-func (h *PublicHandler) GetWhatsAppLink(c *gin.Context) {
-	shopIDStr := c.Param("shopID")
-	productIDStr := c.Param("productID")
+// publicCategories lists the categories of the shop's catalog by name, with their product counts
+// Products without a category are not listed
+func publicCategories(db *gorm.DB, shopID uuid.UUID) ([]dto.PublicCategoryResponse, error) {
+	categories := []dto.PublicCategoryResponse{}
+	err := db.Model(&models.Product{}).
+		Select("products.category AS name, COUNT(*) AS product_count, "+
+			"COUNT(*) FILTER (WHERE products.stock > "+heldStockSQL+") AS in_stock_count").
+		Where("products.shop_id = ? AND products.category <> ''", shopID).
+		Group("products.category").
+		Order("products.category").
+		Scan(&categories).Error
+	return categories, err
+}
 
-	shopID, err := uuid.Parse(shopIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shop ID"})
+// GetPublicCategories - categories of the shop's catalog with their product counts, for navigation
+func (h *PublicHandler) GetPublicCategories(c *gin.Context) {
+	shop, ok := h.findPublicShop(c)
+	if !ok {
 		return
 	}
 
-	productID, err := uuid.Parse(productIDStr)
+	categories, err := publicCategories(h.db, shop.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"shop":       publicShopInfo(shop),
+		"categories": categories,
+		"total":      len(categories),
+	})
+}
+
+// publicWhatsApp loads the product of a WhatsApp route with its link, in the customer's language
+// On failure the response is already written
+func (h *PublicHandler) publicWhatsApp(c *gin.Context) (models.Shop, models.Product, publicMessage, dto.PublicProductResponse, bool) {
+	var product models.Product
 	// Get shop (for WhatsApp number)
-	var shop models.Shop
-	if err := h.db.Where("id = ? AND active = true", shopID).First(&shop).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
-		return
+	shop, ok := h.findPublicShop(c)
+	if !ok {
+		return shop, product, publicMessage{}, dto.PublicProductResponse{}, false
+	}
+
+	productID, err := uuid.Parse(c.Param("productID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return shop, product, publicMessage{}, dto.PublicProductResponse{}, false
 	}
 
 	// Get product - must belong to this shop (multi-tenant)
-	var product models.Product
-	if err := h.db.Where("id = ? AND shop_id = ?", productID, shopID).First(&product).Error; err != nil {
+	if err := h.db.Where("id = ? AND shop_id = ?", productID, shop.ID).First(&product).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
-		return
+		return shop, product, publicMessage{}, dto.PublicProductResponse{}, false
+	}
+
+	// Same message as the catalog: customer's language, displayed price
+	message := customerMessage(c, h.db, shop)
+	catalog, err := loadPublicCatalog(h.db, shop, message)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product"})
+		return shop, product, message, dto.PublicProductResponse{}, false
 	}
+	return shop, product, message, catalog.response(product), true
+}
 
-	whatsappLink := buildWhatsAppLink(shop.WhatsAppNumber, product.Name)
+// GetWhatsAppLink - returns the WhatsApp redirect link for a specific product
+// whatsapp_redirect leads to the same conversation and counts the inquiry
+func (h *PublicHandler) GetWhatsAppLink(c *gin.Context) {
+	shop, product, message, resp, ok := h.publicWhatsApp(c)
+	if !ok {
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"product_id":    product.ID,
-		"product_name":  product.Name,
-		"whatsapp_link": whatsappLink,
-		"shop_name":     shop.Name,
+		"product_id":        product.ID,
+		"product_name":      product.Name,
+		"whatsapp_link":     resp.WhatsAppLink,
+		"whatsapp_redirect": resp.WhatsAppRedirect,
+		"whatsapp_contact":  resp.WhatsAppContact,
+		"language":          message.language,
+		"shop_name":         shop.Name,
 	})
 }
@@ -0,0 +1,46 @@
+package handlers
+
+import "testing"
+
+func TestCheckPublicBaseURL(t *testing.T) {
+	tests := []struct {
+		base    string
+		wantErr bool
+	}{
+		{"https://shop.example.com", false},
+		{"https://shop.example.com/", false},
+		{"http://localhost:8080", false},
+		{"https://example.com/boutique", false},
+		{"", true},
+		{"shop.example.com", true},
+		{"ftp://shop.example.com", true},
+		{"https://", true},
+		{"https://shop.example.com/?x=1", true},
+		{"javascript:alert(1)", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("PUBLIC_BASE_URL", tt.base)
+		if err := CheckPublicBaseURL(); (err != nil) != tt.wantErr {
+			t.Errorf("CheckPublicBaseURL(%q) = %v, want error %v", tt.base, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	t.Setenv("PUBLIC_BASE_URL", "https://shop.example.com/")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/s/electro-casa", "https://shop.example.com/s/electro-casa"},
+		{"/uploads/a.jpg", "https://shop.example.com/uploads/a.jpg"},
+		{"https://cdn.example.com/a.jpg", "https://cdn.example.com/a.jpg"}, // Already absolute
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := publicURL(tt.path); got != tt.want {
+			t.Errorf("publicURL(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
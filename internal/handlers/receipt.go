@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/receipt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReceiptHandler struct {
+	db *gorm.DB
+}
+
+func NewReceiptHandler(db *gorm.DB) *ReceiptHandler {
+	return &ReceiptHandler{db: db}
+}
+
+// nextInvoiceNumber reserves the next invoice number of a shop
+// Must run inside the SQL transaction creating the sale: the counter row stays locked
+// until commit and a rollback releases the number, so the sequence has no gaps
+func nextInvoiceNumber(tx *gorm.DB, shopID uuid.UUID) (int64, error) {
+	var number int64
+	err := tx.Raw(`INSERT INTO invoice_counters (shop_id, last_number) VALUES (?, 1)
+		ON CONFLICT (shop_id) DO UPDATE SET last_number = invoice_counters.last_number + 1
+		RETURNING last_number`, shopID).Scan(&number).Error
+	return number, err
+}
+
+// buildReceipt maps a stored sale to what is printed
+func buildReceipt(shop models.Shop, t models.Transaction) receipt.Receipt {
+	r := receipt.Receipt{
+		ShopName:       shop.Name,
+		WhatsAppNumber: shop.WhatsAppNumber,
+		Currency:       shop.Currency,
+		Date:           t.CreatedAt,
+		Subtotal:       t.UnitPrice.Mul(t.Quantity),
+		Discount:       t.Discount,
+		DiscountReason: t.DiscountReason,
+		NetAmount:      t.NetAmount,
+		TaxRate:        t.TaxRate,
+		TaxAmount:      t.TaxAmount,
+		Total:          t.Amount,
+	}
+	if t.InvoiceNumber != nil {
+		r.InvoiceNumber = *t.InvoiceNumber
+	}
+
+	name := "Article"
+	if t.Product != nil {
+		name = t.Product.Name
+	} else if t.ProductName != "" {
+		name = t.ProductName // Product purged
+	}
+	r.Lines = []receipt.Line{{
+		Name:      name,
+		Quantity:  t.Quantity,
+		UnitPrice: t.UnitPrice,
+		Total:     r.Subtotal,
+	}}
+
+	for _, p := range t.Payments {
+		r.Payments = append(r.Payments, receipt.Payment{Method: string(p.Method), Amount: p.Amount})
+	}
+	return r
+}
+
+// GetReceipt - renders a sale as a PDF invoice or a thermal printer receipt
+// ?format=pdf (default) | text, ?width=58 | 80 (text only, default 80)
+func (h *ReceiptHandler) GetReceipt(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	// CRITICAL: Always filter by shopID from JWT to ensure isolation
+	// The product may have been deleted since the sale: the receipt still shows its name
+	var transaction models.Transaction
+	err = h.db.Preload("Product", withDeleted).
+		Preload("Payments").
+		Where("id = ? AND shop_id = ?", transactionID, shopID).
+		First(&transaction).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if transaction.Type != models.TransactionSale || transaction.InvoiceNumber == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Receipts are only available for sales"})
+		return
+	}
+
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	r := buildReceipt(shop, transaction)
+	filename := receipt.FormatInvoiceNumber(r.InvoiceNumber)
+
+	switch c.DefaultQuery("format", "pdf") {
+	case "pdf":
+		c.Header("Content-Disposition", `inline; filename="`+filename+`.pdf"`)
+		c.Data(http.StatusOK, "application/pdf", r.PDF())
+	case "text":
+		paper := c.DefaultQuery("width", "80")
+		width := receipt.Width80mm
+		switch paper {
+		case "80":
+		case "58":
+			width = receipt.Width58mm
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "width must be 58 or 80"})
+			return
+		}
+		c.Header("Content-Disposition", `inline; filename="`+filename+`-`+paper+`mm.txt"`)
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(r.Text(width)))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be pdf or text"})
+	}
+}
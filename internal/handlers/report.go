@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
 
 	"electronic-shop/internal/dto"
 	"electronic-shop/internal/middleware"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -27,35 +33,43 @@ func (h *ReportHandler) GetDashboard(c *gin.Context) {
 		return
 	}
 
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
 	// Total sales (sum of all Sale transactions)
-	var totalSales float64
+	var totalSales money.Amount
 	h.db.Model(&models.Transaction{}).
 		Where("shop_id = ? AND type = ?", shopID, models.TransactionSale).
-		Select("COALESCE(SUM(amount), 0)").
+		Select("COALESCE(SUM(amount), 0)::bigint").
 		Scan(&totalSales)
 
 	// Total expenses (Expense + Withdrawal)
-	var totalExpenses float64
+	var totalExpenses money.Amount
 	h.db.Model(&models.Transaction{}).
 		Where("shop_id = ? AND type IN ?", shopID, []string{
 			string(models.TransactionExpense),
 			string(models.TransactionWithdrawal),
 		}).
-		Select("COALESCE(SUM(amount), 0)").
+		Select("COALESCE(SUM(amount), 0)::bigint").
 		Scan(&totalExpenses)
 
-	// Low stock products (stock < 5)
+	// Low stock products: at or below their reorder point (or the shop default)
 	var lowStockProducts []models.Product
-	h.db.Where("shop_id = ? AND stock < 5", shopID).
+	h.db.Where("shop_id = ? AND stock <= COALESCE(reorder_point, ?)", shopID, shop.DefaultReorderPoint).
+		Order("stock").
 		Find(&lowStockProducts)
 
 	var lowStockItems []dto.LowStockItem
 	for _, p := range lowStockProducts {
 		lowStockItems = append(lowStockItems, dto.LowStockItem{
-			ID:       p.ID,
-			Name:     p.Name,
-			Stock:    p.Stock,
-			Category: p.Category,
+			ID:           p.ID,
+			Name:         p.Name,
+			Stock:        p.Stock,
+			ReorderPoint: p.EffectiveReorderPoint(shop.DefaultReorderPoint),
+			Category:     p.Category,
 		})
 	}
 	if lowStockItems == nil {
@@ -79,6 +93,7 @@ func (h *ReportHandler) GetDashboard(c *gin.Context) {
 	netProfit := totalSales - totalExpenses
 
 	c.JSON(http.StatusOK, dto.DashboardResponse{
+		Currency:          shop.Currency,
 		TotalSales:        totalSales,
 		TotalExpenses:     totalExpenses,
 		NetProfit:         netProfit,
@@ -88,3 +103,290 @@ func (h *ReportHandler) GetDashboard(c *gin.Context) {
 		TotalItemsSold:    totalItemsSold,
 	})
 }
+
+// GetPaymentReport - takings per payment method per day, for end-of-day reconciliation
+// Supports date_from / date_to (YYYY-MM-DD) like GetTransactions
+func (h *ReportHandler) GetPaymentReport(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	type row struct {
+		Day    string
+		Method string
+		Amount money.Amount
+		Count  int64
+	}
+
+	query := h.db.Table("payments").
+		Select("TO_CHAR(transactions.created_at, 'YYYY-MM-DD') AS day, payments.method, SUM(payments.amount)::bigint AS amount, COUNT(*) AS count").
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Where("payments.shop_id = ? AND transactions.shop_id = ? AND transactions.type = ?", shopID, shopID, models.TransactionSale)
+	query = applyDateRange(query, "transactions.created_at", c.Query("date_from"), c.Query("date_to"))
+
+	var rows []row
+	if err := query.Group("day, payments.method").Order("day DESC, payments.method").Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute payment report"})
+		return
+	}
+
+	resp := dto.PaymentReportResponse{
+		Days:   []dto.DailyPaymentSummary{},
+		Totals: []dto.PaymentMethodTotal{},
+	}
+	totals := map[string]*dto.PaymentMethodTotal{}
+	for _, r := range rows {
+		if len(resp.Days) == 0 || resp.Days[len(resp.Days)-1].Date != r.Day {
+			resp.Days = append(resp.Days, dto.DailyPaymentSummary{Date: r.Day, Methods: []dto.PaymentMethodTotal{}})
+		}
+		day := &resp.Days[len(resp.Days)-1]
+		day.Methods = append(day.Methods, dto.PaymentMethodTotal{Method: r.Method, Amount: r.Amount, Count: r.Count})
+		day.Total += r.Amount
+
+		if totals[r.Method] == nil {
+			totals[r.Method] = &dto.PaymentMethodTotal{Method: r.Method}
+		}
+		totals[r.Method].Amount += r.Amount
+		totals[r.Method].Count += r.Count
+		resp.Total += r.Amount
+	}
+
+	for _, m := range []models.PaymentMethod{models.PaymentCash, models.PaymentCard, models.PaymentBankTransfer, models.PaymentMobileMoney} {
+		if t := totals[string(m)]; t != nil {
+			resp.Totals = append(resp.Totals, *t)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTaxReport - VAT collected on sales per rate over a period, for filing
+// Supports date_from / date_to (YYYY-MM-DD) like GetTransactions
+func (h *ReportHandler) GetTaxReport(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	dateFrom := c.Query("date_from")
+	dateTo := c.Query("date_to")
+
+	query := h.db.Model(&models.Transaction{}).
+		Select("tax_rate, SUM(net_amount)::bigint AS net_amount, SUM(tax_amount)::bigint AS tax_amount, SUM(amount)::bigint AS gross, COUNT(*) AS count").
+		Where("shop_id = ? AND type = ?", shopID, models.TransactionSale)
+	query = applyDateRange(query, "created_at", dateFrom, dateTo)
+
+	var rates []dto.TaxRateTotals
+	if err := query.Group("tax_rate").Order("tax_rate").Scan(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute tax report"})
+		return
+	}
+
+	resp := dto.TaxReportResponse{DateFrom: dateFrom, DateTo: dateTo, Rates: rates}
+	if resp.Rates == nil {
+		resp.Rates = []dto.TaxRateTotals{}
+	}
+	for _, r := range resp.Rates {
+		resp.NetAmount += r.NetAmount
+		resp.TaxAmount += r.TaxAmount
+		resp.Gross += r.Gross
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetReorderSuggestions - products to reorder, most urgent first
+// Sales velocity is the average daily quantity sold over the shop's window (?days= overrides it).
+// A product is listed when its available stock is at or below its reorder point, or when it
+// is expected to run out within ?within_days= (default 14). The suggested quantity is the
+// product's reorder quantity, or enough to cover ?cover_days= (default 30) of sales above the reorder point.
+func (h *ReportHandler) GetReorderSuggestions(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	days := queryInt(c, "days", shop.SalesVelocityDays, 1, 365)
+	withinDays := queryInt(c, "within_days", 14, 0, 365)
+	coverDays := queryInt(c, "cover_days", 30, 1, 365)
+
+	var rows []struct {
+		models.Product
+		Reserved int
+		Sold     int
+	}
+	err := h.db.Model(&models.Product{}).
+		Select(`products.*, `+heldStockSQL+` AS reserved,
+			COALESCE((SELECT SUM(t.quantity) FROM transactions t
+				WHERE t.product_id = products.id AND t.type = 'Sale' AND t.created_at >= ?), 0) AS sold`,
+			time.Now().AddDate(0, 0, -days)).
+		Where("shop_id = ?", shopID).
+		Find(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute reorder suggestions"})
+		return
+	}
+
+	suggestions := []dto.ReorderSuggestion{}
+	for _, r := range rows {
+		reorderPoint := r.EffectiveReorderPoint(shop.DefaultReorderPoint)
+		available := r.Stock - r.Reserved
+		velocity := float64(r.Sold) / float64(days)
+
+		var daysLeft *float64
+		if velocity > 0 {
+			d := math.Round(math.Max(float64(available), 0)/velocity*10) / 10
+			daysLeft = &d
+		}
+		if available > reorderPoint && (daysLeft == nil || *daysLeft > float64(withinDays)) {
+			continue
+		}
+
+		suggested := r.ReorderQuantity
+		if suggested <= 0 {
+			target := int(math.Ceil(velocity*float64(coverDays))) + reorderPoint
+			suggested = max(target-available, reorderPoint+1-available)
+		}
+
+		suggestions = append(suggestions, dto.ReorderSuggestion{
+			ProductID:         r.ID,
+			SKU:               r.SKU,
+			Name:              r.Name,
+			Category:          r.Category,
+			Stock:             r.Stock,
+			Reserved:          r.Reserved,
+			ReorderPoint:      reorderPoint,
+			DailySales:        math.Round(velocity*100) / 100,
+			DaysUntilStockout: daysLeft,
+			SuggestedQuantity: suggested,
+		})
+	}
+
+	// Soonest stockout first; products that do not sell come last, emptiest first
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if (a.DaysUntilStockout == nil) != (b.DaysUntilStockout == nil) {
+			return a.DaysUntilStockout != nil
+		}
+		if a.DaysUntilStockout != nil && *a.DaysUntilStockout != *b.DaysUntilStockout {
+			return *a.DaysUntilStockout < *b.DaysUntilStockout
+		}
+		return a.Stock-a.Reserved < b.Stock-b.Reserved
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"sales_window_days": days,
+		"suggestions":       suggestions,
+		"total":             len(suggestions),
+	})
+}
+
+// whatsAppReportIntervals - periods of the WhatsApp report, as understood by date_trunc
+var whatsAppReportIntervals = []string{"day", "week", "month"}
+
+// GetWhatsAppReport - WhatsApp inquiries (clicks on the tracked links) per product and per period
+// Supports date_from / date_to (YYYY-MM-DD, default the last 30 days) and ?interval=day|week|month.
+// An inquiry is converted when its product is sold within ?attribution_days= (default 7) after the click.
+func (h *ReportHandler) GetWhatsAppReport(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	dateFrom := c.Query("date_from")
+	if _, err := time.Parse("2006-01-02", dateFrom); err != nil {
+		dateFrom = time.Now().AddDate(0, 0, -29).Format("2006-01-02")
+	}
+	dateTo := c.Query("date_to")
+	interval := c.DefaultQuery("interval", "day")
+	if !slices.Contains(whatsAppReportIntervals, interval) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be day, week or month"})
+		return
+	}
+	attributionDays := queryInt(c, "attribution_days", 7, 0, 90)
+
+	clicks := func() *gorm.DB {
+		query := h.db.Model(&models.WhatsAppClick{}).Where("shop_id = ?", shopID)
+		return applyDateRange(query, "created_at", dateFrom, dateTo)
+	}
+	stats := `COUNT(*) AS inquiries, COUNT(DISTINCT ip_hash) AS unique_visitors,
+		COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM transactions t
+			WHERE t.shop_id = whats_app_clicks.shop_id AND t.product_id = whats_app_clicks.product_id AND t.type = 'Sale'
+			AND t.created_at >= whats_app_clicks.created_at
+			AND t.created_at <= whats_app_clicks.created_at + make_interval(days => ?))) AS converted`
+
+	resp := dto.WhatsAppReportResponse{
+		DateFrom:        dateFrom,
+		DateTo:          dateTo,
+		Interval:        interval,
+		AttributionDays: attributionDays,
+		Products:        []dto.WhatsAppProductStats{},
+		Periods:         []dto.WhatsAppPeriodStats{},
+	}
+	if err := clicks().Select(stats, attributionDays).Scan(&resp.WhatsAppLeadStats).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute WhatsApp report"})
+		return
+	}
+
+	// Purged products have no ID left, their clicks are grouped by name
+	err := clicks().
+		Select(`product_id,
+			COALESCE((SELECT p.name FROM products p WHERE p.id = whats_app_clicks.product_id), MAX(product_name)) AS product_name,
+			MAX(created_at) AS last_inquiry_at, `+stats, attributionDays).
+		Group("product_id, CASE WHEN product_id IS NULL THEN product_name END").
+		Order("inquiries DESC, last_inquiry_at DESC").
+		Scan(&resp.Products).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute WhatsApp report"})
+		return
+	}
+
+	// interval is one of whatsAppReportIntervals
+	err = clicks().
+		Select("TO_CHAR(DATE_TRUNC('"+interval+"', created_at), 'YYYY-MM-DD') AS period, "+stats, attributionDays).
+		Group("period").
+		Order("period").
+		Scan(&resp.Periods).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute WhatsApp report"})
+		return
+	}
+
+	resp.ConversionRate = conversionRate(resp.WhatsAppLeadStats)
+	for i := range resp.Products {
+		resp.Products[i].ConversionRate = conversionRate(resp.Products[i].WhatsAppLeadStats)
+	}
+	for i := range resp.Periods {
+		resp.Periods[i].ConversionRate = conversionRate(resp.Periods[i].WhatsAppLeadStats)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// conversionRate - share of inquiries followed by a sale, in % rounded to 0.1
+func conversionRate(s dto.WhatsAppLeadStats) float64 {
+	if s.Inquiries == 0 {
+		return 0
+	}
+	return math.Round(float64(s.Converted)/float64(s.Inquiries)*1000) / 10
+}
+
+// queryInt reads an integer query parameter within [min, max], or returns fallback
+func queryInt(c *gin.Context, key string, fallback, minValue, maxValue int) int {
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil || v < minValue || v > maxValue {
+		return fallback
+	}
+	return v
+}
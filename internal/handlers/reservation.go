@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/events"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// heldStockSQL - quantity of a product held by running reservations, for use in WHERE clauses on products
+const heldStockSQL = `COALESCE((SELECT SUM(r.quantity) FROM reservations r
+	WHERE r.product_id = products.id AND r.status = 'Active' AND r.expires_at > NOW()), 0)`
+
+var errReservationNotFound = errors.New("reservation not found")
+
+type ReservationHandler struct {
+	db     *gorm.DB
+	alerts *notify.StockAlerter
+	hub    events.Hub
+}
+
+func NewReservationHandler(db *gorm.DB, alerts *notify.StockAlerter, hub events.Hub) *ReservationHandler {
+	return &ReservationHandler{db: db, alerts: alerts, hub: hub}
+}
+
+// reservationHold returns the default hold duration, configurable with RESERVATION_HOLD_HOURS (default 24)
+func reservationHold() time.Duration {
+	hours := 24
+	if v, err := strconv.Atoi(os.Getenv("RESERVATION_HOLD_HOURS")); err == nil && v > 0 {
+		hours = v
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// reservedQuantity returns the quantity of a product held by running reservations, except one
+func reservedQuantity(db *gorm.DB, productID uuid.UUID, except *models.Reservation) (int, error) {
+	query := db.Model(&models.Reservation{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("product_id = ? AND status = ? AND expires_at > ?", productID, models.ReservationActive, time.Now())
+	if except != nil {
+		query = query.Where("id <> ?", except.ID)
+	}
+
+	var reserved int
+	err := query.Scan(&reserved).Error
+	return reserved, err
+}
+
+// reservedByProduct returns the held quantity of every product of the shop that has running reservations
+func reservedByProduct(db *gorm.DB, shopID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ProductID uuid.UUID
+		Reserved  int
+	}
+	if err := db.Model(&models.Reservation{}).
+		Select("product_id, SUM(quantity) AS reserved").
+		Where("shop_id = ? AND status = ? AND expires_at > ?", shopID, models.ReservationActive, time.Now()).
+		Group("product_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	reserved := make(map[uuid.UUID]int, len(rows))
+	for _, r := range rows {
+		reserved[r.ProductID] = r.Reserved
+	}
+	return reserved, nil
+}
+
+// GetReservations - lists the shop's reservations, soonest expiry first
+// Optional ?status=Active|Completed|Cancelled|Expired
+func (h *ReservationHandler) GetReservations(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query := h.db.Where("shop_id = ?", shopID).Preload("Product", withDeleted)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var reservations []models.Reservation
+	if err := query.Order("expires_at").Find(&reservations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservations"})
+		return
+	}
+	// Only SuperAdmin can see purchase price
+	role := middleware.GetRoleFromContext(c)
+	for i := range reservations {
+		hidePurchasePrice(reservations[i].Product, role)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reservations": reservations,
+		"total":        len(reservations),
+	})
+}
+
+// CreateReservation - holds stock for a customer until pickup or expiry
+func (h *ReservationHandler) CreateReservation(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.CreateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold := reservationHold()
+	if req.HoldHours > 0 {
+		hold = time.Duration(req.HoldHours) * time.Hour
+	}
+
+	reservation := models.Reservation{
+		ProductID:     req.ProductID,
+		Quantity:      req.Quantity,
+		CustomerName:  req.CustomerName,
+		CustomerPhone: req.CustomerPhone,
+		Note:          req.Note,
+		Status:        models.ReservationActive,
+		ExpiresAt:     time.Now().Add(hold),
+		ShopID:        shopID, // Always from JWT
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		// Same row lock as sales, so a sale and a reservation cannot both take the last unit
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND shop_id = ?", req.ProductID, shopID).
+			First(&product).Error; err != nil {
+			return errors.New("product not found")
+		}
+
+		reserved, err := reservedQuantity(tx, product.ID, nil)
+		if err != nil {
+			return errors.New("failed to check stock")
+		}
+		if available := product.Stock - reserved; available < req.Quantity {
+			return fmt.Errorf("insufficient stock: available %d", available)
+		}
+
+		return tx.Create(&reservation).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.db.Preload("Product").First(&reservation, "id = ?", reservation.ID)
+	hidePurchasePrice(reservation.Product, middleware.GetRoleFromContext(c))
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// PickupReservation - the customer came: converts the reservation into a Sale
+// The sale is priced and paid like POST /api/transactions, for the reserved product and quantity
+func (h *ReservationHandler) PickupReservation(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	reservationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation ID"})
+		return
+	}
+
+	var req dto.PickupReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role := middleware.GetRoleFromContext(c)
+	var transaction models.Transaction
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var reservation models.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND shop_id = ?", reservationID, shopID).
+			First(&reservation).Error; err != nil {
+			return errReservationNotFound
+		}
+		if !reservation.Holds(time.Now()) {
+			return fmt.Errorf("reservation is %s", reservationState(reservation))
+		}
+
+		productID := reservation.ProductID
+		saleReq := dto.CreateTransactionRequest{
+			Type:          string(models.TransactionSale),
+			ProductID:     &productID,
+			Quantity:      reservation.Quantity,
+			Comment:       req.Comment,
+			Payments:      req.Payments,
+			LineDiscount:  req.LineDiscount,
+			OrderDiscount: req.OrderDiscount,
+			PriceOverride: req.PriceOverride,
+			CouponCode:    req.CouponCode,
+		}
+		if saleReq.Comment == "" {
+			saleReq.Comment = "Réservation " + reservation.CustomerName
+		}
+
+		var err error
+		transaction, err = recordTransaction(tx, shopID, role, saleReq, &reservation)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&reservation).Updates(map[string]interface{}{
+			"status":         models.ReservationCompleted,
+			"transaction_id": transaction.ID,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, errReservationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.db.Preload("Product", withDeleted).Preload("Payments").First(&transaction, "id = ?", transaction.ID)
+	publishTransaction(h.hub, transaction)
+	if transaction.ProductID != nil {
+		h.alerts.Check(shopID, *transaction.ProductID)
+		publishStock(h.db, h.hub, shopID, *transaction.ProductID)
+	}
+	hidePurchasePrice(transaction.Product, role)
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// CancelReservation - releases the held stock before expiry
+func (h *ReservationHandler) CancelReservation(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	reservationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation ID"})
+		return
+	}
+
+	var reservation models.Reservation
+	if err := h.db.Where("id = ? AND shop_id = ?", reservationID, shopID).First(&reservation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	// The status condition keeps a concurrent pickup from being overwritten
+	result := h.db.Model(&models.Reservation{}).
+		Where("id = ? AND shop_id = ? AND status = ?", reservationID, shopID, models.ReservationActive).
+		Update("status", models.ReservationCancelled)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel reservation"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reservation is " + reservationState(reservation)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reservation cancelled"})
+}
+
+// reservationState describes why a reservation no longer holds stock, for error messages
+func reservationState(r models.Reservation) string {
+	if r.Status == models.ReservationActive {
+		return "expired"
+	}
+	return map[models.ReservationStatus]string{
+		models.ReservationCompleted: "already picked up",
+		models.ReservationCancelled: "cancelled",
+		models.ReservationExpired:   "expired",
+	}[r.Status]
+}
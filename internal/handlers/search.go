@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"strings"
+	"unicode"
+
+	"electronic-shop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// publicPerPage is the page size of the public catalog when only page is given
+const publicPerPage = 24
+
+// publicRelatedLimit is how many related products a product page shows
+const publicRelatedLimit = 4
+
+// searchTSQuery turns what a customer typed into a prefix tsquery: "écran sams" gives "écran:* & sams:*"
+// Only letters and digits are kept, so the input can never be a tsquery syntax error
+func searchTSQuery(input string) string {
+	words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// withDisplayPrice returns displayPrice as an SQL expression on products, for filters and sorting
+// When the stored and displayed bases differ, the product's tax rate is joined in
+func withDisplayPrice(query *gorm.DB, shop models.Shop) (*gorm.DB, string) {
+	const joinTaxRates = "LEFT JOIN tax_rates tr ON tr.shop_id = products.shop_id AND tr.class = products.tax_class"
+	switch {
+	case shop.PricesIncludeTax && !shop.DisplayTaxInclusive:
+		return query.Joins(joinTaxRates), "ROUND(products.selling_price * 100 / (100 + COALESCE(tr.rate, 0)))"
+	case !shop.PricesIncludeTax && shop.DisplayTaxInclusive:
+		return query.Joins(joinTaxRates), "(products.selling_price + ROUND(products.selling_price * COALESCE(tr.rate, 0) / 100))"
+	}
+	return query, "products.selling_price"
+}
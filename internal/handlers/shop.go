@@ -1,16 +1,25 @@
 package handlers
 
 import (
+	"cmp"
+	"errors"
 	"net/http"
+	"strings"
 
 	"electronic-shop/internal/dto"
 	"electronic-shop/internal/middleware"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/phone"
+	"electronic-shop/internal/slug"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var errSlugTaken = errors.New("slug taken")
+
 type ShopHandler struct {
 	db *gorm.DB
 }
@@ -28,7 +37,7 @@ func (h *ShopHandler) GetShop(c *gin.Context) {
 	}
 
 	var shop models.Shop
-	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+	if err := h.db.Preload("TaxRates").First(&shop, "id = ?", shopID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
 		return
 	}
@@ -36,7 +45,8 @@ func (h *ShopHandler) GetShop(c *gin.Context) {
 	c.JSON(http.StatusOK, shop)
 }
 
-// UpdateWhatsApp - updates the shop's WhatsApp number (SuperAdmin only)
+// UpdateWhatsApp - updates the shop's WhatsApp number, stored in E.164 (SuperAdmin only)
+// A national number is read in the shop's country, or in req.Country which then becomes the shop's
 func (h *ShopHandler) UpdateWhatsApp(c *gin.Context) {
 	shopID, ok := middleware.GetShopIDFromContext(c)
 	if !ok {
@@ -50,9 +60,25 @@ func (h *ShopHandler) UpdateWhatsApp(c *gin.Context) {
 		return
 	}
 
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+	country, ok := phone.LookupCountry(cmp.Or(req.Country, shop.Country, phone.DefaultCountry))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": phone.ErrCountry.Error()})
+		return
+	}
+	number, err := phone.Normalize(req.WhatsAppNumber, country.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "whatsapp_number: " + err.Error()})
+		return
+	}
+
 	result := h.db.Model(&models.Shop{}).
 		Where("id = ?", shopID).
-		Update("whats_app_number", req.WhatsAppNumber)
+		Updates(map[string]interface{}{"whats_app_number": number, "country": country.Code})
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update WhatsApp number"})
@@ -65,7 +91,238 @@ func (h *ShopHandler) UpdateWhatsApp(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":          "WhatsApp number updated successfully",
-		"whatsapp_number":  req.WhatsAppNumber,
+		"message":         "WhatsApp number updated successfully",
+		"whatsapp_number": number,
+		"country":         country.Code,
+	})
+}
+
+// shopSlugTaken reports whether another shop uses the slug, now or formerly
+func shopSlugTaken(db *gorm.DB, s string, shopID uuid.UUID) bool {
+	var n int64
+	db.Raw(`SELECT (SELECT COUNT(*) FROM shops WHERE slug = ? AND id <> ?)
+		+ (SELECT COUNT(*) FROM shop_slug_redirects WHERE slug = ? AND shop_id <> ?)`,
+		s, shopID, s, shopID).Scan(&n)
+	return n > 0
+}
+
+// shopSlugs returns the current slug of the shop and its former ones, most recent first
+func shopSlugs(db *gorm.DB, shopID uuid.UUID) (string, []string, error) {
+	var shop models.Shop
+	if err := db.First(&shop, "id = ?", shopID).Error; err != nil {
+		return "", nil, err
+	}
+	previous := []string{}
+	err := db.Model(&models.ShopSlugRedirect{}).Where("shop_id = ?", shopID).
+		Order("created_at DESC").Pluck("slug", &previous).Error
+	return shop.Slug, previous, err
+}
+
+// GetSlug - returns the shop's slug and its former slugs, which still redirect (SuperAdmin only)
+func (h *ShopHandler) GetSlug(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	current, previous, err := shopSlugs(h.db, shopID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"slug":           current,
+		"previous_slugs": previous,
+	})
+}
+
+// UpdateSlug - renames the shop's public URL (SuperAdmin only)
+// The former slug keeps redirecting to the new one, so printed links keep working
+func (h *ShopHandler) UpdateSlug(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.UpdateSlugRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	newSlug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if err := slug.Validate(newSlug); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var shop models.Shop
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shop, "id = ?", shopID).Error; err != nil {
+			return err
+		}
+		if shop.Slug == newSlug {
+			return nil
+		}
+		if shopSlugTaken(tx, newSlug, shopID) {
+			return errSlugTaken
+		}
+
+		// Taking back a former slug: it is no longer a redirect
+		if err := tx.Where("slug = ? AND shop_id = ?", newSlug, shopID).Delete(&models.ShopSlugRedirect{}).Error; err != nil {
+			return err
+		}
+		if shop.Slug != "" {
+			if err := tx.Create(&models.ShopSlugRedirect{Slug: shop.Slug, ShopID: shopID}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&shop).Update("slug", newSlug).Error
+	})
+	switch {
+	case errors.Is(err, errSlugTaken) || isUniqueViolation(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug already taken"})
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update slug"})
+		return
+	}
+
+	current, previous, _ := shopSlugs(h.db, shopID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Slug updated successfully",
+		"slug":           current,
+		"previous_slugs": previous,
+	})
+}
+
+// GetTaxSettings - returns the shop's VAT settings and rates per tax class
+func (h *ShopHandler) GetTaxSettings(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var shop models.Shop
+	if err := h.db.Preload("TaxRates").First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	rates := shop.TaxRates
+	if rates == nil {
+		rates = []models.TaxRate{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"prices_include_tax":    shop.PricesIncludeTax,
+		"display_tax_inclusive": shop.DisplayTaxInclusive,
+		"rates":                 rates,
+	})
+}
+
+// UpdateTaxSettings - updates VAT settings; sent rates replace all existing classes (SuperAdmin only)
+// Past transactions keep the rate they were recorded with
+func (h *ShopHandler) UpdateTaxSettings(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.UpdateTaxSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, r := range req.Rates {
+		if seen[r.Class] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Duplicate tax class: " + r.Class})
+			return
+		}
+		seen[r.Class] = true
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{}
+		if req.PricesIncludeTax != nil {
+			updates["prices_include_tax"] = *req.PricesIncludeTax
+		}
+		if req.DisplayTaxInclusive != nil {
+			updates["display_tax_inclusive"] = *req.DisplayTaxInclusive
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Shop{}).Where("id = ?", shopID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if req.Rates == nil {
+			return nil
+		}
+		if err := tx.Where("shop_id = ?", shopID).Delete(&models.TaxRate{}).Error; err != nil {
+			return err
+		}
+		for _, r := range req.Rates {
+			rate := models.TaxRate{ShopID: shopID, Class: r.Class, Name: r.Name, Rate: r.Rate}
+			if err := tx.Create(&rate).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tax settings"})
+		return
+	}
+
+	h.GetTaxSettings(c)
+}
+
+// UpdateInventorySettings - default reorder point and sales window of reorder suggestions (SuperAdmin only)
+func (h *ShopHandler) UpdateInventorySettings(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.UpdateInventorySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.DefaultReorderPoint != nil {
+		updates["default_reorder_point"] = *req.DefaultReorderPoint
+	}
+	if req.SalesVelocityDays != nil {
+		updates["sales_velocity_days"] = *req.SalesVelocityDays
+	}
+	if len(updates) > 0 {
+		if err := h.db.Model(&models.Shop{}).Where("id = ?", shopID).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory settings"})
+			return
+		}
+	}
+
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default_reorder_point": shop.DefaultReorderPoint,
+		"sales_velocity_days":   shop.SalesVelocityDays,
 	})
 }
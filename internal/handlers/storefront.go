@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/storefront"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// storefrontSorts - sort options of the HTML catalog, as in dto.PublicProductQuery
+var storefrontSorts = []storefront.SortOption{
+	{Value: "name", Label: "Nom"},
+	{Value: "price_asc", Label: "Prix croissant"},
+	{Value: "price_desc", Label: "Prix décroissant"},
+	{Value: "newest", Label: "Nouveautés"},
+	{Value: "popularity", Label: "Meilleures ventes"},
+}
+
+// StorefrontHandler serves the server-rendered catalog of each shop at /s/:slug
+// Pages are built from the same data as the public JSON routes
+type StorefrontHandler struct {
+	db *gorm.DB
+}
+
+func NewStorefrontHandler(db *gorm.DB) *StorefrontHandler {
+	return &StorefrontHandler{db: db}
+}
+
+// render writes a page; a template error is logged and answered with a plain 500
+func (h *StorefrontHandler) render(c *gin.Context, status int, page string, data any) {
+	body, err := storefront.Render(page, data)
+	if err != nil {
+		log.Printf("storefront: %s page: %v", page, err)
+		c.String(http.StatusInternalServerError, "Erreur interne")
+		return
+	}
+	if status == http.StatusOK {
+		c.Header("Cache-Control", "public, max-age=60")
+		// WhatsApp messages follow the customer's language
+		c.Header("Vary", "Accept-Language")
+	}
+	c.Data(status, "text/html; charset=utf-8", body)
+}
+
+func (h *StorefrontHandler) notFound(c *gin.Context, shop *models.Shop) {
+	page := storefront.Page{Meta: storefront.Meta{Title: "Page introuvable"}}
+	if shop != nil {
+		page.Shop = storefront.Shop{Name: shop.Name, URL: shopPath(*shop)}
+		page.Meta.Title += " - " + shop.Name
+	}
+	h.render(c, http.StatusNotFound, storefront.PageNotFound, page)
+}
+
+// findShop loads the shop of the :slug parameter
+// A UUID or a former slug is redirected to the current slug, the one canonical URL of the page
+func (h *StorefrontHandler) findShop(c *gin.Context) (models.Shop, bool) {
+	key := c.Param("slug")
+	shop, _, err := lookupPublicShop(h.db, key)
+	if err != nil {
+		h.notFound(c, nil)
+		return shop, false
+	}
+	if key != shop.Slug && shop.Slug != "" {
+		redirectToSlug(c, key, shop.Slug)
+		return shop, false
+	}
+	return shop, true
+}
+
+func shopPath(shop models.Shop) string {
+	return "/s/" + shop.Slug
+}
+
+func categoryPath(shop models.Shop, category string) string {
+	return shopPath(shop) + "/c/" + url.PathEscape(category)
+}
+
+func productPath(shop models.Shop, productID uuid.UUID) string {
+	return shopPath(shop) + "/p/" + productID.String()
+}
+
+// storefrontPage - what every page of the shop shows around its content
+func (h *StorefrontHandler) storefrontPage(c *gin.Context, shop models.Shop, currentCategory string) storefront.Page {
+	page := storefront.Page{
+		Shop:  storefront.Shop{Name: shop.Name, URL: shopPath(shop)},
+		Query: c.Query("q"),
+	}
+	categories, err := publicCategories(h.db, shop.ID)
+	if err != nil {
+		log.Printf("storefront: categories of shop %s: %v", shop.ID, err)
+	}
+	for _, cat := range categories {
+		page.Categories = append(page.Categories, storefront.Category{
+			Name:    cat.Name,
+			URL:     categoryPath(shop, cat.Name),
+			Count:   cat.ProductCount,
+			Current: cat.Name == currentCategory,
+		})
+	}
+	return page
+}
+
+// productView maps a public product response to the page; PurchasePrice is not part of either
+func (h *StorefrontHandler) productView(shop models.Shop, p dto.PublicProductResponse) storefront.Product {
+	view := storefront.Product{
+		Name:         p.Name,
+		Description:  p.Description,
+		Category:     p.Category,
+		URL:          productPath(shop, p.ID),
+		Price:        money.Format(p.SellingPrice, shop.Currency),
+		Promotion:    p.Promotion,
+		TaxNote:      "HT",
+		StockStatus:  p.StockStatus,
+		Available:    p.Stock > 0,
+		ImageURL:     p.ImageURL,
+		WhatsAppLink: p.WhatsAppRedirect, // Counted in the WhatsApp report
+	}
+	if p.Category != "" {
+		view.CategoryURL = categoryPath(shop, p.Category)
+	}
+	if p.PromotionalPrice > 0 {
+		view.PromotionalPrice = money.Format(p.PromotionalPrice, shop.Currency)
+	}
+	if p.PriceIncludesTax {
+		view.TaxNote = "TTC"
+	}
+	return view
+}
+
+// summary shortens a text for a meta description
+func summary(text string, maxRunes int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	runes := []rune(text)[:maxRunes-1]
+	return strings.TrimSpace(string(runes)) + "…"
+}
+
+// Home - the shop's catalog, with search (?q), sorting (?sort) and pages (?page)
+func (h *StorefrontHandler) Home(c *gin.Context) {
+	shop, ok := h.findShop(c)
+	if !ok {
+		return
+	}
+	h.renderList(c, shop, "")
+}
+
+// Category - the products of one category
+func (h *StorefrontHandler) Category(c *gin.Context) {
+	shop, ok := h.findShop(c)
+	if !ok {
+		return
+	}
+	h.renderList(c, shop, c.Param("category"))
+}
+
+func (h *StorefrontHandler) renderList(c *gin.Context, shop models.Shop, category string) {
+	params := dto.PublicProductQuery{
+		Q:        c.Query("q"),
+		Category: category,
+		Page:     queryInt(c, "page", 1, 1, 10000),
+		PerPage:  publicPerPage,
+	}
+	for _, s := range storefrontSorts {
+		if c.Query("sort") == s.Value {
+			params.Sort = s.Value
+		}
+	}
+
+	result, err := listPublicProducts(h.db, shop, params, customerMessage(c, h.db, shop))
+	if err != nil {
+		log.Printf("storefront: products of shop %s: %v", shop.ID, err)
+		c.String(http.StatusInternalServerError, "Erreur interne")
+		return
+	}
+
+	path := shopPath(shop)
+	heading := "Tous les produits"
+	if category != "" {
+		path = categoryPath(shop, category)
+		heading = category
+	}
+	if params.Q != "" {
+		heading = "Résultats pour « " + params.Q + " »"
+	}
+
+	page := storefront.ListPage{
+		Page:       h.storefrontPage(c, shop, category),
+		Heading:    heading,
+		Products:   []storefront.Product{},
+		Total:      result.Total,
+		PageNumber: result.Page,
+		TotalPages: result.TotalPages(),
+	}
+	if category != "" && !slices.ContainsFunc(page.Categories, func(cat storefront.Category) bool { return cat.Current }) {
+		h.notFound(c, &shop)
+		return
+	}
+	for _, p := range result.Products {
+		page.Products = append(page.Products, h.productView(shop, p))
+	}
+
+	sorted := params.Sort
+	if sorted == "" && params.Q == "" {
+		sorted = "name"
+	}
+	for _, s := range storefrontSorts {
+		s.Selected = s.Value == sorted
+		page.Sorts = append(page.Sorts, s)
+	}
+	if params.Q != "" {
+		// Search results are sorted by relevance unless asked otherwise
+		page.Sorts = append([]storefront.SortOption{{Value: "", Label: "Pertinence", Selected: sorted == ""}}, page.Sorts...)
+	}
+
+	// Links to other pages keep the search and the sort
+	pageURL := func(n int) string {
+		values := url.Values{}
+		if params.Q != "" {
+			values.Set("q", params.Q)
+		}
+		if params.Sort != "" {
+			values.Set("sort", params.Sort)
+		}
+		if n > 1 {
+			values.Set("page", strconv.Itoa(n))
+		}
+		if len(values) == 0 {
+			return path
+		}
+		return path + "?" + values.Encode()
+	}
+	if result.Page > 1 {
+		page.PrevURL = pageURL(result.Page - 1)
+	}
+	if result.Page < page.TotalPages {
+		page.NextURL = pageURL(result.Page + 1)
+	}
+
+	page.Meta = storefront.Meta{
+		Title:       heading + " - " + shop.Name,
+		Description: strconv.FormatInt(result.Total, 10) + " produits disponibles chez " + shop.Name + ", commande sur WhatsApp.",
+		URL:         publicURL(pageURL(result.Page)),
+		Type:        "website",
+	}
+	if category == "" && params.Q == "" {
+		page.Meta.Title = shop.Name
+	}
+	for _, p := range page.Products {
+		if p.ImageURL != "" {
+			page.Meta.Image = publicURL(p.ImageURL)
+			break
+		}
+	}
+
+	h.render(c, http.StatusOK, storefront.PageList, page)
+}
+
+// Product - the product page with its gallery and related products
+func (h *StorefrontHandler) Product(c *gin.Context) {
+	shop, ok := h.findShop(c)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("productID"))
+	if err != nil {
+		h.notFound(c, &shop)
+		return
+	}
+
+	detail, err := loadPublicProduct(h.db, shop, productID, customerMessage(c, h.db, shop))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		h.notFound(c, &shop)
+		return
+	}
+	if err != nil {
+		log.Printf("storefront: product %s of shop %s: %v", productID, shop.ID, err)
+		c.String(http.StatusInternalServerError, "Erreur interne")
+		return
+	}
+
+	product := h.productView(shop, detail.PublicProductResponse)
+	for _, img := range detail.Images {
+		alt := img.AltText
+		if alt == "" {
+			alt = product.Name
+		}
+		product.Images = append(product.Images, storefront.Image{URL: img.URL, Alt: alt})
+	}
+
+	page := storefront.ProductPage{
+		Page:    h.storefrontPage(c, shop, detail.Category),
+		Product: product,
+	}
+	for _, p := range detail.Related {
+		page.Related = append(page.Related, h.productView(shop, p))
+	}
+
+	description := summary(detail.Description, 160)
+	if description == "" {
+		description = product.Name + " chez " + shop.Name
+	}
+	price := detail.SellingPrice
+	if detail.PromotionalPrice > 0 {
+		price = detail.PromotionalPrice
+	}
+	page.Meta = storefront.Meta{
+		Title:         product.Name + " - " + shop.Name,
+		Description:   description,
+		URL:           publicURL(product.URL),
+		Type:          "product",
+		PriceAmount:   price.String(),
+		PriceCurrency: shop.Currency,
+	}
+	if product.ImageURL != "" {
+		page.Meta.Image = publicURL(product.ImageURL)
+	} else if len(product.Images) > 0 {
+		page.Meta.Image = publicURL(product.Images[0].URL)
+	}
+
+	h.render(c, http.StatusOK, storefront.PageProduct, page)
+}
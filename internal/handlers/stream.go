@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"electronic-shop/internal/events"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// streamHeartbeat keeps idle connections open through proxies that close silent ones
+const streamHeartbeat = 25 * time.Second
+
+type StreamHandler struct {
+	hub events.Hub
+}
+
+func NewStreamHandler(hub events.Hub) *StreamHandler {
+	return &StreamHandler{hub: hub}
+}
+
+// stockChange - one product of a stock.changed event
+type stockChange struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	SKU      string    `json:"sku,omitempty"`
+	Stock    int       `json:"stock"`
+	LowStock bool      `json:"low_stock"`
+}
+
+// publish sends an event to the shop's live clients; events are best effort, failures are only logged
+func publish(hub events.Hub, shopID uuid.UUID, eventType string, data interface{}) {
+	e, err := events.New(shopID, eventType, data)
+	if err != nil {
+		log.Printf("events: %s of shop %s: %v", eventType, shopID, err)
+		return
+	}
+	hub.Publish(e)
+}
+
+// publishTransaction announces a committed transaction
+// The product is left out: its purchase price is not for every connected role
+func publishTransaction(hub events.Hub, t models.Transaction) {
+	t.Product = nil
+	publish(hub, t.ShopID, events.TypeTransactionCreated, t)
+}
+
+// publishStock announces the current stock of products whose stock changes have been committed
+func publishStock(db *gorm.DB, hub events.Hub, shopID uuid.UUID, productIDs ...uuid.UUID) {
+	if len(productIDs) == 0 {
+		return
+	}
+
+	var shop models.Shop
+	if err := db.First(&shop, "id = ?", shopID).Error; err != nil {
+		return
+	}
+	var products []models.Product
+	if err := db.Where("shop_id = ? AND id IN ?", shopID, productIDs).Find(&products).Error; err != nil {
+		return
+	}
+
+	changes := make([]stockChange, 0, len(products))
+	for _, p := range products {
+		changes = append(changes, stockChange{
+			ID:       p.ID,
+			Name:     p.Name,
+			SKU:      p.SKU,
+			Stock:    p.Stock,
+			LowStock: p.Stock <= p.EffectiveReorderPoint(shop.DefaultReorderPoint),
+		})
+	}
+	publish(hub, shopID, events.TypeStockChanged, gin.H{"products": changes})
+}
+
+// Stream - Server-Sent Events of the shop: transaction.created and stock.changed
+// Missed events are not replayed: on each (re)connection the client gets a "ready" event
+// and should reload the dashboard before applying the following events
+func (h *StreamHandler) Stream(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ch, unsubscribe := h.hub.Subscribe(shopID)
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx: do not buffer the stream
+
+	c.Render(-1, sse.Event{
+		Event: "ready",
+		Retry: 3000,
+		Data:  gin.H{"shop_id": shopID, "at": time.Now()},
+	})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatUint(e.ID, 10),
+				Event: e.Type,
+				Data:  e.Data,
+			})
+			return true
+		case <-heartbeat.C:
+			c.Render(-1, sse.Event{Event: "ping", Data: time.Now().Unix()})
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
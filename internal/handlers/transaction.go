@@ -2,23 +2,74 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"electronic-shop/internal/dto"
+	"electronic-shop/internal/events"
 	"electronic-shop/internal/middleware"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/notify"
+	"electronic-shop/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionHandler struct {
-	db *gorm.DB
+	db     *gorm.DB
+	alerts *notify.StockAlerter
+	hub    events.Hub
 }
 
-func NewTransactionHandler(db *gorm.DB) *TransactionHandler {
-	return &TransactionHandler{db: db}
+func NewTransactionHandler(db *gorm.DB, alerts *notify.StockAlerter, hub events.Hub) *TransactionHandler {
+	return &TransactionHandler{db: db, alerts: alerts, hub: hub}
+}
+
+// applyDateRange filters column on the date_from / date_to query params (YYYY-MM-DD)
+// Invalid dates are ignored, matching the historical behavior of GetTransactions
+func applyDateRange(query *gorm.DB, column, dateFrom, dateTo string) *gorm.DB {
+	// date_from : début de journée (00:00:00)
+	if dateFrom != "" {
+		t, err := time.Parse("2006-01-02", dateFrom)
+		if err == nil {
+			query = query.Where(column+" >= ?", t)
+		}
+	}
+
+	// date_to : fin de journée (23:59:59)
+	if dateTo != "" {
+		t, err := time.Parse("2006-01-02", dateTo)
+		if err == nil {
+			query = query.Where(column+" <= ?", t.Add(24*time.Hour-time.Second))
+		}
+	}
+
+	return query
+}
+
+// validatePayments checks the tenders of a transaction against its final amount
+// Sales need at least one payment; when payments are given they must sum to the amount
+func validatePayments(txType string, payments []dto.PaymentRequest, amount money.Amount) error {
+	if txType == string(models.TransactionSale) && len(payments) == 0 {
+		return errors.New("payments are required for Sale transactions")
+	}
+	if len(payments) == 0 {
+		return nil
+	}
+
+	var sum money.Amount
+	for _, p := range payments {
+		sum += p.Amount
+	}
+	if sum != amount {
+		return fmt.Errorf("payments total %s does not match amount %s", sum, amount)
+	}
+	return nil
 }
 
 // GetTransactions - returns all transactions for the authenticated user's shop
@@ -33,33 +84,25 @@ func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 	dateFrom := c.Query("date_from")
 	dateTo := c.Query("date_to")
 
-	query := h.db.Where("shop_id = ?", shopID).Preload("Product")
+	// Unscoped: past sales still show products that have been deleted since
+	query := h.db.Where("shop_id = ?", shopID).Preload("Product", withDeleted).Preload("Payments")
 
 	if transactionType != "" {
 		query = query.Where("type = ?", transactionType)
 	}
 
-	// date_from : début de journée (00:00:00)
-	if dateFrom != "" {
-		t, err := time.Parse("2006-01-02", dateFrom)
-		if err == nil {
-			query = query.Where("created_at >= ?", t)
-		}
-	}
-
-	// date_to : fin de journée (23:59:59)
-	if dateTo != "" {
-		t, err := time.Parse("2006-01-02", dateTo)
-		if err == nil {
-			query = query.Where("created_at <= ?", t.Add(24*time.Hour-time.Second))
-		}
-	}
+	query = applyDateRange(query, "created_at", dateFrom, dateTo)
 
 	var transactions []models.Transaction
 	if err := query.Order("created_at DESC").Find(&transactions).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
 		return
 	}
+	// Only SuperAdmin can see purchase price
+	role := middleware.GetRoleFromContext(c)
+	for i := range transactions {
+		hidePurchasePrice(transactions[i].Product, role)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"transactions": transactions,
@@ -81,56 +124,196 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
+	isSale := req.Type == string(models.TransactionSale)
+	if !isSale {
+		if req.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "amount is required for " + req.Type + " transactions"})
+			return
+		}
+		if req.LineDiscount != nil || req.OrderDiscount != nil || req.PriceOverride {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "discounts and price override only apply to Sale transactions"})
+			return
+		}
+	}
+
+	role := middleware.GetRoleFromContext(c)
+
 	// Use a DB transaction for atomicity
 	var transaction models.Transaction
 
 	err := h.db.Transaction(func(tx *gorm.DB) error {
-		// If it's a Sale, validate product stock
-		if req.Type == string(models.TransactionSale) {
-			if req.ProductID == nil {
-				return errors.New("product_id is required for Sale transactions")
-			}
-			if req.Quantity <= 0 {
-				return errors.New("quantity must be greater than 0 for Sales")
-			}
+		var err error
+		transaction, err = recordTransaction(tx, shopID, role, req, nil)
+		return err
+	})
 
-			// Fetch product - MUST belong to same shop
-			var product models.Product
-			if err := tx.Where("id = ? AND shop_id = ?", *req.ProductID, shopID).First(&product).Error; err != nil {
-				return errors.New("product not found")
-			}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-			// CRITICAL: Prevent negative stock
-			if product.Stock < req.Quantity {
-				return errors.New("insufficient stock: available " + string(rune('0'+product.Stock)))
-			}
+	// Reload with product and payment info
+	h.db.Preload("Product", withDeleted).Preload("Payments").First(&transaction, "id = ?", transaction.ID)
+	publishTransaction(h.hub, transaction)
+	if transaction.ProductID != nil {
+		h.alerts.Check(shopID, *transaction.ProductID)
+		publishStock(h.db, h.hub, shopID, *transaction.ProductID)
+	}
+
+	hidePurchasePrice(transaction.Product, role)
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// recordTransaction saves a transaction, its payments and, for a sale, the stock deduction
+// It must run inside a DB transaction. pickup is the reservation a sale fulfills, if any:
+// its held quantity is then available to this sale
+func recordTransaction(tx *gorm.DB, shopID uuid.UUID, role string, req dto.CreateTransactionRequest, pickup *models.Reservation) (models.Transaction, error) {
+	isSale := req.Type == string(models.TransactionSale)
+
+	transaction := models.Transaction{
+		Type:      models.TransactionType(req.Type),
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		Amount:    req.Amount,
+		NetAmount: req.Amount, // No VAT on expenses and withdrawals
+		Comment:   req.Comment,
+		ShopID:    shopID, // Always from JWT
+	}
+
+	// If it's a Sale, validate product stock and price it server-side
+	if isSale {
+		if req.ProductID == nil {
+			return models.Transaction{}, errors.New("product_id is required for Sale transactions")
+		}
+		if req.Quantity <= 0 {
+			return models.Transaction{}, errors.New("quantity must be greater than 0 for Sales")
+		}
+
+		// Fetch product - MUST belong to same shop
+		// FOR UPDATE: a concurrent sale of the same product waits until this one commits,
+		// then sees the reduced stock
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND shop_id = ?", *req.ProductID, shopID).
+			First(&product).Error; err != nil {
+			return models.Transaction{}, errors.New("product not found")
+		}
+		transaction.ProductName = product.Name
+
+		// CRITICAL: Prevent negative stock
+		// Units held by reservations are not for sale, except those of the reservation picked up
+		reserved, err := reservedQuantity(tx, product.ID, pickup)
+		if err != nil {
+			return models.Transaction{}, errors.New("failed to check stock")
+		}
+		if available := product.Stock - reserved; available < req.Quantity {
+			return models.Transaction{}, fmt.Errorf("insufficient stock: available %d", available)
+		}
+
+		// Best running promotion (automatic, or unlocked by the coupon)
+		now := time.Now()
+		coupon := normalizeCoupon(req.CouponCode)
+		promotions, err := runningPromotions(tx, shopID, now, false)
+		if err != nil {
+			return models.Transaction{}, errors.New("failed to load promotions")
+		}
+		if coupon != "" && !couponApplies(promotions, product, coupon, now) {
+			return models.Transaction{}, errors.New("invalid or expired coupon code")
+		}
+		promo := bestPromotion(promotions, product, req.Quantity, coupon, now)
 
-			// Deduct stock atomically
-			if err := tx.Model(&product).Update("stock", product.Stock-req.Quantity).Error; err != nil {
-				return errors.New("failed to update stock")
+		// VAT of the product's tax class (0% when the shop has not configured it)
+		var shop models.Shop
+		if err := tx.First(&shop, "id = ?", shopID).Error; err != nil {
+			return models.Transaction{}, errors.New("shop not found")
+		}
+		taxRates, err := shopTaxRates(tx, shopID)
+		if err != nil {
+			return models.Transaction{}, errors.New("failed to load tax rates")
+		}
+		policy := taxPolicy{
+			Rate:             taxRates[taxClassOf(product)],
+			PricesIncludeTax: shop.PricesIncludeTax,
+		}
+
+		// The amount always comes from the product price, never from the client
+		quote, err := priceSale(product, req, role, promo, policy)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+
+		// Consume one use of the promotion; the condition guards the usage limit under concurrency
+		if promo != nil {
+			result := tx.Model(&models.Promotion{}).
+				Where("id = ? AND (max_uses = 0 OR uses_count < max_uses)", promo.ID).
+				Update("uses_count", gorm.Expr("uses_count + 1"))
+			if result.Error != nil {
+				return models.Transaction{}, errors.New("failed to apply promotion")
 			}
+			if result.RowsAffected == 0 {
+				return models.Transaction{}, errors.New("promotion usage limit reached")
+			}
+			transaction.PromotionID = &promo.ID
+			transaction.CouponCode = promo.CouponCode
 		}
+		transaction.UnitPrice = quote.UnitPrice
+		transaction.Discount = quote.Discount
+		transaction.DiscountReason = quote.DiscountReason
+		transaction.PriceOverride = req.PriceOverride
+		transaction.Amount = quote.Amount
+		transaction.NetAmount = quote.NetAmount
+		transaction.TaxAmount = quote.TaxAmount
+		transaction.TaxRate = quote.TaxRate
 
-		// Create transaction record
-		transaction = models.Transaction{
-			Type:      models.TransactionType(req.Type),
-			ProductID: req.ProductID,
-			Quantity:  req.Quantity,
-			Amount:    req.Amount,
-			Comment:   req.Comment,
-			ShopID:    shopID, // Always from JWT
+		// Gap-free invoice numbering: released if anything below fails
+		invoiceNumber, err := nextInvoiceNumber(tx, shopID)
+		if err != nil {
+			return models.Transaction{}, errors.New("failed to assign invoice number")
 		}
+		transaction.InvoiceNumber = &invoiceNumber
 
-		return tx.Create(&transaction).Error
-	})
+		// Deduct stock atomically: relative to the stored value and only if enough is left,
+		// so stock can never go negative even if the row lock above were bypassed
+		result := tx.Model(&models.Product{}).
+			Where("id = ? AND shop_id = ? AND stock >= ?", product.ID, shopID, req.Quantity+reserved).
+			Updates(map[string]interface{}{
+				"stock":   gorm.Expr("stock - ?", req.Quantity),
+				"version": gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return models.Transaction{}, errors.New("failed to update stock")
+		}
+		if result.RowsAffected == 0 {
+			return models.Transaction{}, errors.New("insufficient stock")
+		}
+	}
 
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if err := validatePayments(req.Type, req.Payments, transaction.Amount); err != nil {
+		return models.Transaction{}, err
 	}
 
-	// Reload with product info
-	h.db.Preload("Product").First(&transaction, "id = ?", transaction.ID)
+	// Create transaction record
+	if err := tx.Create(&transaction).Error; err != nil {
+		return models.Transaction{}, err
+	}
 
-	c.JSON(http.StatusCreated, transaction)
+	// Record each tender
+	for _, p := range req.Payments {
+		payment := models.Payment{
+			TransactionID: transaction.ID,
+			Method:        models.PaymentMethod(p.Method),
+			Amount:        p.Amount,
+			ShopID:        shopID,
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return models.Transaction{}, errors.New("failed to record payment")
+		}
+		transaction.Payments = append(transaction.Payments, payment)
+	}
+
+	if err := webhooks.Enqueue(tx, shopID, webhooks.EventTransactionCreated, transaction); err != nil {
+		return models.Transaction{}, errors.New("failed to queue webhooks")
+	}
+
+	return transaction, nil
 }
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/events"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to TEST_DATABASE_URL and migrates the schema, or skips the test
+// Use a throwaway database: tests create their own shop and remove it afterwards
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.Shop{},
+		&models.TaxRate{},
+		&models.Product{},
+		&models.Transaction{},
+		&models.Payment{},
+		&models.InvoiceCounter{},
+		&models.Promotion{},
+		&models.Reservation{},
+		&models.StockAlert{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.WebhookAttempt{},
+	); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	return db
+}
+
+// createTestShop creates a shop and deletes it with everything it owns when the test ends
+func createTestShop(t *testing.T, db *gorm.DB) models.Shop {
+	t.Helper()
+
+	shop := models.Shop{Name: "Test", WhatsAppNumber: "+212600000000"}
+	shop.Slug = "test-" + uuid.NewString()[:8]
+	if err := db.Create(&shop).Error; err != nil {
+		t.Fatalf("create shop: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, model := range []interface{}{&models.Payment{}, &models.Transaction{}, &models.InvoiceCounter{}, &models.Reservation{}} {
+			db.Where("shop_id = ?", shop.ID).Delete(model)
+		}
+		db.Unscoped().Where("shop_id = ?", shop.ID).Delete(&models.Product{})
+		db.Delete(&shop)
+	})
+	return shop
+}
+
+// TestConcurrentSales fires more simultaneous sales than there is stock: the row lock and the
+// conditional decrement must accept exactly the stock, and invoice numbers must have no gap
+func TestConcurrentSales(t *testing.T) {
+	db := openTestDB(t)
+	shop := createTestShop(t, db)
+
+	const stock, sales = 5, 20
+	product := models.Product{
+		Name:          "iPhone 15",
+		PurchasePrice: money.FromUnits(700),
+		SellingPrice:  money.FromUnits(1000),
+		Stock:         stock,
+		ShopID:        shop.ID,
+	}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewTransactionHandler(db, nil, events.NewMemoryHub())
+	r.POST("/api/transactions", func(c *gin.Context) {
+		c.Set("shopID", shop.ID)
+		c.Set("role", string(models.RoleAdmin))
+	}, h.CreateTransaction)
+
+	// No tax rate and no promotion: the payment equals the selling price
+	body, err := json.Marshal(dto.CreateTransactionRequest{
+		Type:      string(models.TransactionSale),
+		ProductID: &product.ID,
+		Quantity:  1,
+		Payments:  []dto.PaymentRequest{{Method: string(models.PaymentCash), Amount: product.SellingPrice}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes := make([]int, sales)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/transactions", bytes.NewReader(body)))
+			codes[i] = w.Code
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	accepted := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			accepted++
+		case http.StatusBadRequest: // Insufficient stock
+		default:
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+	if accepted != stock {
+		t.Errorf("accepted %d sales, want %d", accepted, stock)
+	}
+
+	var left models.Product
+	if err := db.First(&left, "id = ?", product.ID).Error; err != nil {
+		t.Fatalf("reload product: %v", err)
+	}
+	if left.Stock != 0 {
+		t.Errorf("stock left = %d, want 0", left.Stock)
+	}
+
+	var numbers []int64
+	if err := db.Model(&models.Transaction{}).Where("shop_id = ?", shop.ID).
+		Order("invoice_number").Pluck("invoice_number", &numbers).Error; err != nil {
+		t.Fatalf("load invoice numbers: %v", err)
+	}
+	if len(numbers) != stock {
+		t.Fatalf("%d sales recorded, want %d", len(numbers), stock)
+	}
+	for i, n := range numbers {
+		if n != int64(i+1) {
+			t.Errorf("invoice numbers = %v, want 1 to %d without gap", numbers, stock)
+			break
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errProductInUse = errors.New("a promotion still targets this product")
+
+type TrashHandler struct {
+	db *gorm.DB
+}
+
+func NewTrashHandler(db *gorm.DB) *TrashHandler {
+	return &TrashHandler{db: db}
+}
+
+// withDeleted is a Preload option that also loads soft-deleted products
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// trashRetention returns how long deleted products stay restorable before they can be purged
+// Configurable with PRODUCT_TRASH_RETENTION_DAYS (default 30)
+func trashRetention() time.Duration {
+	days := 30
+	if v, err := strconv.Atoi(os.Getenv("PRODUCT_TRASH_RETENTION_DAYS")); err == nil && v >= 0 {
+		days = v
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func toTrashedResponse(p models.Product, role string) dto.TrashedProductResponse {
+	return dto.TrashedProductResponse{
+		PrivateProductResponse: toPrivateResponse(p, role),
+		DeletedAt:              p.DeletedAt.Time,
+		PurgeableAt:            p.DeletedAt.Time.Add(trashRetention()),
+	}
+}
+
+// GetTrash - lists the shop's soft-deleted products, most recently deleted first
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query := filterProducts(h.db.Unscoped().Where("shop_id = ? AND deleted_at IS NOT NULL", shopID),
+		c.Query("category"), c.Query("search"))
+
+	var products []models.Product
+	if err := query.Order("deleted_at DESC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted products"})
+		return
+	}
+
+	role := middleware.GetRoleFromContext(c)
+	responses := []dto.TrashedProductResponse{}
+	for _, p := range products {
+		responses = append(responses, toTrashedResponse(p, role))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products":       responses,
+		"total":          len(responses),
+		"retention_days": int(trashRetention().Hours() / 24),
+	})
+}
+
+// RestoreProduct - brings a soft-deleted product back into the catalog
+func (h *TrashHandler) RestoreProduct(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var product models.Product
+	if err := h.db.Unscoped().
+		Where("id = ? AND shop_id = ? AND deleted_at IS NOT NULL", productID, shopID).
+		First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted product not found"})
+		return
+	}
+
+	if err := restoreProduct(h.db, shopID, product); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.db.First(&product, "id = ?", productID)
+	role := middleware.GetRoleFromContext(c)
+	c.JSON(http.StatusOK, toPrivateResponse(product, role))
+}
+
+// PurgeProduct - permanently deletes one product from the trash (SuperAdmin only)
+// Only products deleted for longer than the retention period can be purged
+func (h *TrashHandler) PurgeProduct(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var product models.Product
+	if err := h.db.Unscoped().
+		Where("id = ? AND shop_id = ? AND deleted_at IS NOT NULL", productID, shopID).
+		First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted product not found"})
+		return
+	}
+
+	purgeableAt := product.DeletedAt.Time.Add(trashRetention())
+	if time.Now().Before(purgeableAt) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":        "Product is still within the retention period",
+			"purgeable_at": purgeableAt,
+		})
+		return
+	}
+
+	if err := purgeProduct(h.db, shopID, product.ID); err != nil {
+		if errors.Is(err, errProductInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A promotion still targets this product, delete it first"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product permanently deleted"})
+}
+
+// PurgeTrash - permanently deletes every product past the retention period (SuperAdmin only)
+func (h *TrashHandler) PurgeTrash(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var products []models.Product
+	if err := h.db.Unscoped().
+		Where("shop_id = ? AND deleted_at IS NOT NULL AND deleted_at <= ?", shopID, time.Now().Add(-trashRetention())).
+		Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted products"})
+		return
+	}
+
+	results := []dto.BulkResult{}
+	for _, p := range products {
+		result := dto.BulkResult{ProductID: p.ID, Name: p.Name, Status: "deleted"}
+		if err := purgeProduct(h.db, shopID, p.ID); err != nil {
+			result.Status = "skipped"
+			result.Error = "Failed to purge product"
+			if errors.Is(err, errProductInUse) {
+				result.Error = "A promotion still targets this product"
+			}
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, newBulkResponse(results))
+}
+
+// purgeProduct hard-deletes a product
+// Sales and WhatsApp clicks keep their product_name snapshot; their product_id is cleared (for sales, to satisfy the foreign key)
+func purgeProduct(db *gorm.DB, shopID, productID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var promotions int64
+		if err := tx.Model(&models.Promotion{}).
+			Where("shop_id = ? AND product_id = ?", shopID, productID).
+			Count(&promotions).Error; err != nil {
+			return err
+		}
+		if promotions > 0 {
+			return errProductInUse
+		}
+
+		if err := tx.Model(&models.Transaction{}).
+			Where("shop_id = ? AND product_id = ?", shopID, productID).
+			Update("product_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.WhatsAppClick{}).
+			Where("shop_id = ? AND product_id = ?", shopID, productID).
+			Update("product_id", nil).Error; err != nil {
+			return err
+		}
+		// Reservations are meaningless without their product; picked up ones live on as sales
+		if err := tx.Where("shop_id = ? AND product_id = ?", shopID, productID).
+			Delete(&models.Reservation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("shop_id = ? AND product_id = ?", shopID, productID).
+			Delete(&models.StockAlert{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("shop_id = ? AND product_id = ?", shopID, productID).
+			Delete(&models.ProductImage{}).Error; err != nil {
+			return err
+		}
+		// CRITICAL: Always include shopID in delete query
+		return tx.Unscoped().
+			Where("id = ? AND shop_id = ? AND deleted_at IS NOT NULL", productID, shopID).
+			Delete(&models.Product{}).Error
+	})
+}
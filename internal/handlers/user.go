@@ -6,6 +6,7 @@ import (
 	"electronic-shop/internal/dto"
 	"electronic-shop/internal/middleware"
 	"electronic-shop/internal/models"
+	"electronic-shop/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -88,18 +89,26 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		ShopID:   shopID, // Always assign to current shop
 	}
 
-	if err := h.db.Create(&user).Error; err != nil {
+	resp := dto.UserResponse{}
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		resp = dto.UserResponse{
+			ID:     user.ID,
+			Name:   user.Name,
+			Email:  user.Email,
+			Role:   string(user.Role),
+			ShopID: user.ShopID,
+		}
+		return webhooks.Enqueue(tx, shopID, webhooks.EventUserCreated, resp)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.UserResponse{
-		ID:     user.ID,
-		Name:   user.Name,
-		Email:  user.Email,
-		Role:   string(user.Role),
-		ShopID: user.ShopID,
-	})
+	c.JSON(http.StatusCreated, resp)
 }
 
 // DeleteUser - SuperAdmin deletes a user from their shop
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	db *gorm.DB
+}
+
+func NewWebhookHandler(db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{db: db}
+}
+
+func toWebhookResponse(e models.WebhookEndpoint) dto.WebhookEndpointResponse {
+	return dto.WebhookEndpointResponse{
+		ID:          e.ID,
+		URL:         e.URL,
+		Events:      strings.Split(e.Events, ","),
+		Description: e.Description,
+		Active:      e.Active,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+// webhookEvents validates the subscribed events and returns them in storage form
+func webhookEvents(events []string) (string, error) {
+	seen := map[string]bool{}
+	var list []string
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "*" {
+			return "*", nil
+		}
+		if !webhooks.ValidEvent(e) {
+			return "", errors.New("unknown event: " + e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			list = append(list, e)
+		}
+	}
+	return strings.Join(list, ","), nil
+}
+
+// findWebhookEndpoint loads an endpoint of the shop from the :id parameter, answering the error itself
+func (h *WebhookHandler) findWebhookEndpoint(c *gin.Context, shopID uuid.UUID) (models.WebhookEndpoint, bool) {
+	var endpoint models.WebhookEndpoint
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return endpoint, false
+	}
+	if err := h.db.Where("id = ? AND shop_id = ?", endpointID, shopID).First(&endpoint).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return endpoint, false
+	}
+	return endpoint, true
+}
+
+// GetEndpoints - lists the shop's webhook endpoints and the events they can subscribe to
+func (h *WebhookHandler) GetEndpoints(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := h.db.Where("shop_id = ?", shopID).Order("created_at").Find(&endpoints).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	responses := []dto.WebhookEndpointResponse{}
+	for _, e := range endpoints {
+		responses = append(responses, toWebhookResponse(e))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": responses,
+		"events":   webhooks.AllEvents,
+	})
+}
+
+// CreateEndpoint - registers a URL; the response holds the signing secret, shown only this once
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := webhooks.CheckURL(c.Request.Context(), req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := webhookEvents(req.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	endpoint := models.WebhookEndpoint{
+		URL:         req.URL,
+		Secret:      webhooks.NewSecret(),
+		Events:      events,
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+		ShopID:      shopID, // Always from JWT
+	}
+	// Active has a database default: create it active, then switch it off if asked
+	if err := h.db.Create(&endpoint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	if !endpoint.Active {
+		h.db.Model(&endpoint).Update("active", false)
+	}
+
+	resp := toWebhookResponse(endpoint)
+	resp.Secret = endpoint.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// UpdateEndpoint - changes the URL, events, description or active flag
+func (h *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpoint, ok := h.findWebhookEndpoint(c, shopID)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.URL != nil {
+		if err := webhooks.CheckURL(c.Request.Context(), *req.URL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["url"] = *req.URL
+	}
+	if req.Events != nil {
+		events, err := webhookEvents(req.Events)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["events"] = events
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+
+	if len(updates) > 0 {
+		if err := h.db.Model(&endpoint).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+			return
+		}
+	}
+
+	h.db.First(&endpoint, "id = ?", endpoint.ID)
+	c.JSON(http.StatusOK, toWebhookResponse(endpoint))
+}
+
+// RotateSecret - replaces the signing secret; deliveries sent from now on use the new one
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpoint, ok := h.findWebhookEndpoint(c, shopID)
+	if !ok {
+		return
+	}
+
+	endpoint.Secret = webhooks.NewSecret()
+	if err := h.db.Model(&endpoint).Update("secret", endpoint.Secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate secret"})
+		return
+	}
+
+	resp := toWebhookResponse(endpoint)
+	resp.Secret = endpoint.Secret
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteEndpoint - removes an endpoint with its deliveries and their logs
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpoint, ok := h.findWebhookEndpoint(c, shopID)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&models.WebhookDelivery{}).Select("id").Where("endpoint_id = ? AND shop_id = ?", endpoint.ID, shopID)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&models.WebhookAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("endpoint_id = ? AND shop_id = ?", endpoint.ID, shopID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		// CRITICAL: Always include shopID in delete query
+		return tx.Where("id = ? AND shop_id = ?", endpoint.ID, shopID).Delete(&models.WebhookEndpoint{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetDeliveries - delivery log of an endpoint, most recent first
+// Optional ?status=pending|delivered|failed and ?limit (default 50, max 200)
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	endpoint, ok := h.findWebhookEndpoint(c, shopID)
+	if !ok {
+		return
+	}
+
+	query := h.db.Where("endpoint_id = ? AND shop_id = ?", endpoint.ID, shopID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(queryInt(c, "limit", 50, 1, 200)).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"total":      len(deliveries),
+	})
+}
+
+// GetDelivery - one delivery with the log of every attempt
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := h.db.Preload("Logs", func(db *gorm.DB) *gorm.DB { return db.Order("attempt") }).
+		Where("id = ? AND shop_id = ?", deliveryID, shopID).
+		First(&delivery).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver - queues the same event again for the same endpoint, as a new delivery
+// The event ID is kept, so receivers that de-duplicate on it can recognize the redelivery
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := h.db.Where("id = ? AND shop_id = ?", deliveryID, shopID).First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+	if original.Status == models.WebhookPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delivery is still pending"})
+		return
+	}
+
+	var endpoint models.WebhookEndpoint
+	if err := h.db.Where("id = ? AND shop_id = ?", original.EndpointID, shopID).First(&endpoint).Error; err != nil || !endpoint.Active {
+		c.JSON(http.StatusConflict, gin.H{"error": "Webhook is disabled, enable it first"})
+		return
+	}
+
+	delivery := models.WebhookDelivery{
+		EndpointID:    original.EndpointID,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.WebhookPending,
+		NextAttemptAt: time.Now(),
+		ShopID:        shopID, // Always from JWT
+	}
+	if err := h.db.Create(&delivery).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue delivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"electronic-shop/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxReferrerLength - size of WhatsAppClick.Referrer
+const maxReferrerLength = 500
+
+// linkPreviewAgents - crawlers fetching shared links for their preview, which are not customers
+var linkPreviewAgents = []string{"whatsapp/", "facebookexternalhit", "facebot", "twitterbot", "telegrambot", "slackbot", "discordbot", "linkedinbot", "bot", "crawler", "spider"}
+
+// whatsAppRedirectPath - the tracked route to a product's WhatsApp conversation
+// lang pins the template the customer was shown, "" leaves the choice to the redirect request
+func whatsAppRedirectPath(shop models.Shop, productID uuid.UUID, lang string) string {
+	path := "/public/" + cmp.Or(shop.Slug, shop.ID.String()) + "/products/" + productID.String() + "/whatsapp/redirect"
+	if lang != "" {
+		path += "?lang=" + url.QueryEscape(lang)
+	}
+	return path
+}
+
+// hashVisitorIP - keyed hash of a visitor's IP, to count distinct visitors without storing the IP
+// Keyed with CLICK_HASH_SECRET (else JWT_SECRET) and the shop, so hashes cannot be matched across shops
+func hashVisitorIP(shopID uuid.UUID, ip string) string {
+	secret := cmp.Or(os.Getenv("CLICK_HASH_SECRET"), os.Getenv("JWT_SECRET"))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(shopID.String() + "|" + ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isLinkPreview(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, agent := range linkPreviewAgents {
+		if strings.Contains(userAgent, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedirectToWhatsApp - logs a WhatsApp inquiry about the product, then redirects to its wa.me link
+// A failure to log never keeps the customer from the conversation
+func (h *PublicHandler) RedirectToWhatsApp(c *gin.Context) {
+	shop, product, message, resp, ok := h.publicWhatsApp(c)
+	if !ok {
+		return
+	}
+
+	if !isLinkPreview(c.GetHeader("User-Agent")) {
+		referrer := c.Request.Referer()
+		if len(referrer) > maxReferrerLength {
+			referrer = strings.ToValidUTF8(referrer[:maxReferrerLength], "")
+		}
+		click := models.WhatsAppClick{
+			ShopID:      shop.ID,
+			ProductID:   &product.ID,
+			ProductName: product.Name,
+			Language:    message.language,
+			Referrer:    referrer,
+			IPHash:      hashVisitorIP(shop.ID, c.ClientIP()),
+		}
+		if err := h.db.Create(&click).Error; err != nil {
+			log.Printf("whatsapp: click on product %s of shop %s: %v", product.ID, shop.ID, err)
+		}
+	}
+
+	// Each click must reach the server to be counted
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, resp.WhatsAppLink)
+}
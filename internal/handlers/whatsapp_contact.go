@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/phone"
+	"electronic-shop/internal/whatsapp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WhatsAppContactHandler struct {
+	db *gorm.DB
+}
+
+func NewWhatsAppContactHandler(db *gorm.DB) *WhatsAppContactHandler {
+	return &WhatsAppContactHandler{db: db}
+}
+
+// contactFields returns the trimmed label of a contact request and its number in E.164
+// A national number is read in the shop's country
+func (h *WhatsAppContactHandler) contactFields(shopID uuid.UUID, req dto.WhatsAppContactRequest) (string, string, error) {
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		return "", "", errors.New("label must not be blank")
+	}
+	var shop models.Shop
+	h.db.Select("country").First(&shop, "id = ?", shopID)
+	number, err := phone.Normalize(req.Number, cmp.Or(shop.Country, phone.DefaultCountry))
+	if err != nil {
+		return "", "", fmt.Errorf("number: %w", err)
+	}
+	return label, number, nil
+}
+
+// routingResponse - the shop's WhatsApp numbers and how inquiries are routed to them
+func (h *WhatsAppContactHandler) routingResponse(shopID uuid.UUID) (gin.H, error) {
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		return nil, err
+	}
+	contacts := []models.WhatsAppContact{}
+	if err := h.db.Where("shop_id = ?", shopID).Order("label").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	routes := []models.WhatsAppRoute{}
+	if err := h.db.Where("shop_id = ?", shopID).Order("position").Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"fallback_number": shop.WhatsAppNumber, // Set with PUT /api/shops/whatsapp
+		"timezone":        cmp.Or(shop.Timezone, whatsapp.DefaultTimezone),
+		"contacts":        contacts,
+		"routes":          routes,
+	}, nil
+}
+
+// GetContacts - the shop's WhatsApp numbers, routes and fallback number (SuperAdmin only)
+func (h *WhatsAppContactHandler) GetContacts(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	resp, err := h.routingResponse(shopID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch WhatsApp contacts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateContact - adds a labelled WhatsApp number (SuperAdmin only)
+func (h *WhatsAppContactHandler) CreateContact(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.WhatsAppContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	label, number, err := h.contactFields(shopID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contact := models.WhatsAppContact{
+		ShopID: shopID, // Always from JWT
+		Label:  label,
+		Number: number,
+	}
+	if err := h.db.Create(&contact).Error; err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A contact with this label already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create contact"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, contact)
+}
+
+// UpdateContact - changes the label or number of a contact; its routes follow (SuperAdmin only)
+func (h *WhatsAppContactHandler) UpdateContact(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	contactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	var req dto.WhatsAppContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	label, number, err := h.contactFields(shopID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// CRITICAL: Always include shopID in update query
+	result := h.db.Model(&models.WhatsAppContact{}).
+		Where("id = ? AND shop_id = ?", contactID, shopID).
+		Updates(map[string]interface{}{"label": label, "number": number})
+	if result.Error != nil {
+		if isUniqueViolation(result.Error) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A contact with this label already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update contact"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
+		return
+	}
+
+	var contact models.WhatsAppContact
+	h.db.First(&contact, "id = ?", contactID)
+	c.JSON(http.StatusOK, contact)
+}
+
+// DeleteContact - removes a contact no route uses anymore (SuperAdmin only)
+func (h *WhatsAppContactHandler) DeleteContact(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	contactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	var routes int64
+	h.db.Model(&models.WhatsAppRoute{}).Where("shop_id = ? AND contact_id = ?", shopID, contactID).Count(&routes)
+	if routes > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Routes still use this contact, update the routing first"})
+		return
+	}
+
+	// CRITICAL: Always include shopID in delete query
+	result := h.db.Where("id = ? AND shop_id = ?", contactID, shopID).Delete(&models.WhatsAppContact{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete contact"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contact deleted successfully"})
+}
+
+// UpdateRouting - replaces the shop's routes, tried in the given order, and the time zone of their hours (SuperAdmin only)
+func (h *WhatsAppContactHandler) UpdateRouting(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.UpdateWhatsAppRoutingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone, use an IANA name like Africa/Casablanca"})
+			return
+		}
+	}
+
+	var contacts []models.WhatsAppContact
+	if err := h.db.Where("shop_id = ?", shopID).Find(&contacts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch WhatsApp contacts"})
+		return
+	}
+	owned := map[uuid.UUID]bool{}
+	for _, contact := range contacts {
+		owned[contact.ID] = true
+	}
+
+	routes := make([]models.WhatsAppRoute, 0, len(req.Routes))
+	for i, r := range req.Routes {
+		// Contacts of other shops are reported as unknown
+		if !owned[r.ContactID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("routes[%d]: contact not found", i)})
+			return
+		}
+		schedule := whatsapp.Schedule{Days: r.Days, Opens: strings.TrimSpace(r.Opens), Closes: strings.TrimSpace(r.Closes)}
+		if err := schedule.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("routes[%d]: %v", i, err)})
+			return
+		}
+		schedule = schedule.Normalize()
+		routes = append(routes, models.WhatsAppRoute{
+			ShopID:    shopID, // Always from JWT
+			ContactID: r.ContactID,
+			Category:  strings.TrimSpace(r.Category),
+			Days:      schedule.Days,
+			Opens:     schedule.Opens,
+			Closes:    schedule.Closes,
+			Position:  i,
+		})
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if req.Timezone != "" {
+			if err := tx.Model(&models.Shop{}).Where("id = ?", shopID).Update("timezone", req.Timezone).Error; err != nil {
+				return err
+			}
+		}
+		// CRITICAL: Always include shopID in delete query
+		if err := tx.Where("shop_id = ?", shopID).Delete(&models.WhatsAppRoute{}).Error; err != nil {
+			return err
+		}
+		if len(routes) == 0 {
+			return nil
+		}
+		return tx.Create(&routes).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update WhatsApp routing"})
+		return
+	}
+
+	resp, err := h.routingResponse(shopID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch WhatsApp contacts"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"electronic-shop/internal/dto"
+	"electronic-shop/internal/middleware"
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/money"
+	"electronic-shop/internal/whatsapp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WhatsAppTemplateHandler struct {
+	db *gorm.DB
+}
+
+func NewWhatsAppTemplateHandler(db *gorm.DB) *WhatsAppTemplateHandler {
+	return &WhatsAppTemplateHandler{db: db}
+}
+
+// templatePreview renders a template with sample values, so the shop sees what customers will send
+func templatePreview(shop models.Shop, body string) string {
+	return whatsapp.Render(body, whatsapp.Vars{
+		ProductName: "iPhone 15 128 Go",
+		Price:       money.Format(999900, shop.Currency),
+		ProductURL:  publicURL(productPath(shop, uuid.Nil)),
+		SKU:         "IPH15-128",
+		ShopName:    shop.Name,
+	})
+}
+
+// GetTemplates - the shop's WhatsApp message templates per language (SuperAdmin only)
+func (h *WhatsAppTemplateHandler) GetTemplates(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	templates := []models.WhatsAppTemplate{}
+	if err := h.db.Where("shop_id = ?", shopID).Order("language").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default_language": shop.DefaultLanguage,
+		"templates":        templates,
+		"placeholders":     whatsapp.Placeholders,
+		"fallback":         whatsapp.DefaultTemplate, // Used when no template fits
+	})
+}
+
+// PutTemplate - creates or replaces the template of a language (SuperAdmin only)
+func (h *WhatsAppTemplateHandler) PutTemplate(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	lang, err := whatsapp.ParseLanguage(c.Param("lang"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req dto.UpdateWhatsAppTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := whatsapp.ValidateTemplate(req.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var shop models.Shop
+	if err := h.db.First(&shop, "id = ?", shopID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	template := models.WhatsAppTemplate{
+		ShopID:   shopID, // Always from JWT
+		Language: lang,
+		Body:     req.Body,
+	}
+	if err := h.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "shop_id"}, {Name: "language"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"body": req.Body, "updated_at": time.Now()}),
+	}).Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template"})
+		return
+	}
+	h.db.Where("shop_id = ? AND language = ?", shopID, lang).First(&template)
+
+	c.JSON(http.StatusOK, gin.H{
+		"template": template,
+		"preview":  templatePreview(shop, template.Body),
+	})
+}
+
+// DeleteTemplate - removes the template of a language; its customers get the default language's (SuperAdmin only)
+func (h *WhatsAppTemplateHandler) DeleteTemplate(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	lang, err := whatsapp.ParseLanguage(c.Param("lang"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// CRITICAL: Always include shopID in delete query
+	result := h.db.Where("shop_id = ? AND language = ?", shopID, lang).Delete(&models.WhatsAppTemplate{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// UpdateDefaultLanguage - sets the language whose template is used when the customer's has none (SuperAdmin only)
+func (h *WhatsAppTemplateHandler) UpdateDefaultLanguage(c *gin.Context) {
+	shopID, ok := middleware.GetShopIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.UpdateDefaultLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lang, err := whatsapp.ParseLanguage(req.DefaultLanguage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := h.db.Model(&models.Shop{}).Where("id = ?", shopID).Update("default_language", lang)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update default language"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Default language updated successfully",
+		"default_language": lang,
+	})
+}
@@ -0,0 +1,138 @@
+// Package importer reads uploaded spreadsheets (CSV or XLSX) into rows of text.
+//
+// It only knows about cells; what the columns mean is up to the caller.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// MaxRows caps the size of an import to keep a single SQL transaction reasonable
+const MaxRows = 5000
+
+var (
+	ErrUnsupportedFile = errors.New("file must be .csv or .xlsx")
+	ErrEmptyFile       = errors.New("file has no header row")
+	ErrTooManyRows     = errors.New("too many rows (maximum 5000)")
+)
+
+// Table - the header and data rows of the first sheet
+// Header names are lower-cased and trimmed; blank rows are dropped
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Row - the trimmed cells of a data row, exactly len(Header) of them
+// Line is the 1-based line (CSV) or row number (XLSX) to point users at
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// Column returns the index of a header name, or -1
+func (t Table) Column(name string) int {
+	for i, h := range t.Header {
+		if h == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Read parses a CSV or XLSX file, chosen from the file name extension
+func Read(filename string, data []byte) (Table, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(data)
+	case ".xlsx":
+		records, err = readXLSX(data)
+	default:
+		return Table{}, ErrUnsupportedFile
+	}
+	if err != nil {
+		return Table{}, err
+	}
+
+	return newTable(records)
+}
+
+func newTable(records [][]string) (Table, error) {
+	// Skip leading blank lines
+	first := 0
+	for first < len(records) && isBlank(records[first]) {
+		first++
+	}
+	if first == len(records) {
+		return Table{}, ErrEmptyFile
+	}
+
+	t := Table{Header: make([]string, len(records[first]))}
+	for i, h := range records[first] {
+		t.Header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+
+	for n := first + 1; n < len(records); n++ {
+		rec := records[n]
+		if isBlank(rec) {
+			continue
+		}
+		row := Row{Line: n + 1, Cells: make([]string, len(t.Header))}
+		for i := range row.Cells {
+			if i < len(rec) {
+				row.Cells[i] = strings.TrimSpace(rec[i])
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if len(t.Rows) > MaxRows {
+		return Table{}, ErrTooManyRows
+	}
+	return t, nil
+}
+
+// readCSV accepts comma or semicolon separators (French Excel uses ';') and a UTF-8 BOM
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	r := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+
+	var records [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// encoding/csv skips empty lines: pad so line numbers in reports match the file
+		line, _ := r.FieldPos(0)
+		for line > len(records)+1 {
+			records = append(records, nil)
+		}
+		records = append(records, rec)
+	}
+}
+
+func isBlank(rec []string) bool {
+	for _, c := range rec {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidXLSX = errors.New("invalid xlsx file")
+
+const (
+	// maxColumns - Excel's last column is XFD
+	maxColumns = 16384
+	// maxSheetSize caps the unzipped worksheet: MaxRows rows of a product sheet are far below this
+	maxSheetSize = 32 << 20
+	// maxPartSize caps the other unzipped parts (workbook, relationships, shared strings)
+	maxPartSize = 16 << 20
+)
+
+// Just enough of SpreadsheetML to read cell values of the first worksheet
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText is either a plain <t> or runs of <r><t>
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidXLSX
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared, maxPartSize); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrInvalidXLSX
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(f, &sheet, maxSheetSize); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	headerWidth := -1 // Unknown until the first non-blank row
+	for _, row := range sheet.Rows {
+		// Row numbers come from the file: check them before padding allocates anything
+		number := row.Number
+		if number <= len(records) {
+			number = len(records) + 1 // No reference: rows are consecutive
+		}
+		if number > MaxRows+1 {
+			return nil, ErrTooManyRows
+		}
+		// Empty rows are left out of the XML: pad so row numbers stay aligned
+		for number > len(records)+1 {
+			records = append(records, nil)
+		}
+
+		values := map[int]string{}
+		width := 0
+		for i, cell := range row.Cells {
+			col := columnIndex(cell.Ref)
+			if col < 0 {
+				col = i // No reference: cells are consecutive
+			}
+			if col >= maxColumns {
+				return nil, ErrInvalidXLSX
+			}
+			// Cells right of the header would be dropped by newTable: don't store them
+			if headerWidth >= 0 && col >= headerWidth {
+				continue
+			}
+
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, ErrInvalidXLSX
+				}
+				value = shared.Items[idx].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			default:
+				value = cell.Value
+			}
+			if value != "" {
+				values[col] = value
+				width = max(width, col+1)
+			}
+		}
+
+		// Only up to the last non-empty cell, so styled empty cells far right cost nothing
+		rec := make([]string, width)
+		for col, value := range values {
+			rec[col] = value
+		}
+		if headerWidth < 0 && !isBlank(rec) {
+			headerWidth = width
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// firstSheetPath follows workbook.xml and its relationships to the first worksheet
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	wbFile, ok1 := files["xl/workbook.xml"]
+	relsFile, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 {
+		return "", ErrInvalidXLSX
+	}
+	if err := decodeZipXML(wbFile, &wb, maxPartSize); err != nil {
+		return "", err
+	}
+	if err := decodeZipXML(relsFile, &rels, maxPartSize); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", ErrInvalidXLSX
+	}
+
+	for _, r := range rels.Relationships {
+		if r.ID == wb.Sheets[0].RID {
+			if strings.HasPrefix(r.Target, "/") {
+				return strings.TrimPrefix(r.Target, "/"), nil
+			}
+			return path.Join("xl", r.Target), nil
+		}
+	}
+	return "", ErrInvalidXLSX
+}
+
+// decodeZipXML decodes a part of the archive, read up to limit bytes once unzipped
+func decodeZipXML(f *zip.File, v interface{}, limit int64) error {
+	rc, err := f.Open()
+	if err != nil {
+		return ErrInvalidXLSX
+	}
+	defer rc.Close()
+
+	// Guard against zip bombs: a truncated part fails to decode
+	if err := xml.NewDecoder(io.LimitReader(rc, limit)).Decode(v); err != nil {
+		return ErrInvalidXLSX
+	}
+	return nil
+}
+
+// columnIndex converts a cell reference like "C12" to a 0-based column, or -1
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+		if col > maxColumns {
+			return maxColumns // Out of range, without overflowing on long references
+		}
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
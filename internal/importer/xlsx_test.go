@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildXLSX zips a minimal workbook whose first sheet has the given <sheetData> content
+func buildXLSX(t *testing.T, sheetData string, sharedStrings ...string) []byte {
+	t.Helper()
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Produits" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	if len(sharedStrings) > 0 {
+		var b strings.Builder
+		b.WriteString(`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+		for _, s := range sharedStrings {
+			b.WriteString("<si><t>" + s + "</t></si>")
+		}
+		b.WriteString("</sst>")
+		parts["xl/sharedStrings.xml"] = b.String()
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := buildXLSX(t, `
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>Stock</t></is></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><r><t>iPhone </t></r><r><t>15</t></r></is></c><c r="B2"><v>999.5</v></c><c r="C2"><v>3</v></c></row>
+<row r="4"><c r="B4"><v>12</v></c><c r="A4" t="inlineStr"><is><t>Câble</t></is></c></row>`,
+		"Name", " Price ")
+
+	table, err := Read("produits.xlsx", data)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	want := Table{
+		Header: []string{"name", "price", "stock"},
+		Rows: []Row{
+			{Line: 2, Cells: []string{"iPhone 15", "999.5", "3"}},
+			{Line: 4, Cells: []string{"Câble", "12", ""}},
+		},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("Read = %+v, want %+v", table, want)
+	}
+}
+
+func TestReadXLSXBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		sheetData string
+		wantErr   error
+		wantRows  int
+	}{
+		{
+			name:      "hostile row number",
+			sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c></row><row r="30000000"><c r="A30000000"><v>1</v></c></row>`,
+			wantErr:   ErrTooManyRows,
+		},
+		{
+			name:      "row just past MaxRows",
+			sheetData: fmt.Sprintf(`<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c></row><row r="%d"><c><v>1</v></c></row>`, MaxRows+2),
+			wantErr:   ErrTooManyRows,
+		},
+		{
+			name:      "last allowed row",
+			sheetData: fmt.Sprintf(`<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c></row><row r="%d"><c><v>1</v></c></row>`, MaxRows+1),
+			wantRows:  1,
+		},
+		{
+			name:      "column past XFD",
+			sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c><c r="XFE1"><v>1</v></c></row>`,
+			wantErr:   ErrInvalidXLSX,
+		},
+		{
+			name:      "overflowing column reference",
+			sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c><c r="ZZZZZZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`,
+			wantErr:   ErrInvalidXLSX,
+		},
+		{
+			name:      "cells right of the header are dropped",
+			sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c></row><row r="2"><c r="A2"><v>1</v></c><c r="XFD2"><v>2</v></c></row>`,
+			wantRows:  1,
+		},
+		{
+			name:      "styled empty cells before the header",
+			sheetData: `<row r="1"><c r="XFD1" s="1"/></row><row r="2"><c r="A2" t="inlineStr"><is><t>name</t></is></c></row><row r="3"><c r="A3"><v>1</v></c></row>`,
+			wantRows:  1,
+		},
+		{
+			name:      "shared string out of range",
+			sheetData: `<row r="1"><c r="A1" t="s"><v>7</v></c></row>`,
+			wantErr:   ErrInvalidXLSX,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := Read("produits.xlsx", buildXLSX(t, tt.sheetData))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Read error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(table.Rows) != tt.wantRows {
+				t.Errorf("Read rows = %d, want %d", len(table.Rows), tt.wantRows)
+			}
+			for _, row := range table.Rows {
+				if len(row.Cells) != len(table.Header) {
+					t.Errorf("row %d has %d cells, want %d", row.Line, len(row.Cells), len(table.Header))
+				}
+			}
+		})
+	}
+}
+
+func TestReadXLSXSheetTooLarge(t *testing.T) {
+	// Compresses to a few kilobytes, unzips past maxSheetSize
+	padding := strings.Repeat(" ", maxSheetSize)
+	data := buildXLSX(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c></row>`+padding)
+
+	if _, err := Read("produits.xlsx", data); !errors.Is(err, ErrInvalidXLSX) {
+		t.Errorf("Read error = %v, want %v", err, ErrInvalidXLSX)
+	}
+}
+
+func TestReadXLSXNotAZip(t *testing.T) {
+	if _, err := Read("produits.xlsx", []byte("name,price\n")); !errors.Is(err, ErrInvalidXLSX) {
+		t.Errorf("Read error = %v, want %v", err, ErrInvalidXLSX)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want int
+	}{
+		{"A1", 0},
+		{"C12", 2},
+		{"Z3", 25},
+		{"AA3", 26},
+		{"XFD1", maxColumns - 1},
+		{"XFE1", maxColumns},
+		{"12", -1},
+		{"", -1},
+	}
+	for _, tt := range tests {
+		if got := columnIndex(tt.ref); got != tt.want {
+			t.Errorf("columnIndex(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
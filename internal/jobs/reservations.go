@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"electronic-shop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ExpireReservations marks active reservations past their expiry as Expired
+// Availability never depends on this running on time: expired holds are ignored anyway
+func ExpireReservations(db *gorm.DB) func(context.Context) error {
+	return func(ctx context.Context) error {
+		result := db.WithContext(ctx).Model(&models.Reservation{}).
+			Where("status = ? AND expires_at <= ?", models.ReservationActive, time.Now()).
+			Update("status", models.ReservationExpired)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("job reservations: %d expired", result.RowsAffected)
+		}
+		return nil
+	}
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"electronic-shop/internal/money"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -12,13 +14,23 @@ import (
 // ========================
 
 type Shop struct {
-	ID             uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	Name           string    `gorm:"not null" json:"name"`
-	Active         bool      `gorm:"default:true" json:"active"`
-	WhatsAppNumber string    `gorm:"not null" json:"whatsapp_number"`
-	CreatedAt      time.Time `json:"created_at"`
-	Users          []User    `gorm:"foreignKey:ShopID" json:"-"`
-	Products       []Product `gorm:"foreignKey:ShopID" json:"-"`
+	ID                  uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Name                string    `gorm:"not null" json:"name"`
+	Slug                string    `gorm:"type:varchar(60);uniqueIndex" json:"slug"` // Public URLs: /public/<slug>/products
+	Active              bool      `gorm:"default:true" json:"active"`
+	WhatsAppNumber      string    `gorm:"not null" json:"whatsapp_number"`                              // E.164; fallback when no WhatsApp route applies
+	Country             string    `gorm:"type:varchar(2);default:'MA'" json:"country"`                  // ISO 3166 code of the shop's national phone numbers
+	Currency            string    `gorm:"type:varchar(3);default:'MAD'" json:"currency"`                // ISO 4217 code of every amount of the shop
+	PricesIncludeTax    bool      `gorm:"default:true" json:"prices_include_tax"`                       // Selling prices are entered tax-inclusive
+	DisplayTaxInclusive bool      `gorm:"default:true" json:"display_tax_inclusive"`                    // Public catalog shows tax-inclusive prices
+	DefaultReorderPoint int       `gorm:"default:4" json:"default_reorder_point"`                       // For products without their own reorder point
+	SalesVelocityDays   int       `gorm:"default:30" json:"sales_velocity_days"`                        // Recent sales window of reorder suggestions
+	DefaultLanguage     string    `gorm:"type:varchar(35);default:'fr'" json:"default_language"`        // WhatsApp message language when the customer's has no template
+	Timezone            string    `gorm:"type:varchar(64);default:'Africa/Casablanca'" json:"timezone"` // Of the WhatsApp routes' opening hours
+	TaxRates            []TaxRate `gorm:"foreignKey:ShopID" json:"tax_rates,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	Users               []User    `gorm:"foreignKey:ShopID" json:"-"`
+	Products            []Product `gorm:"foreignKey:ShopID" json:"-"`
 }
 
 func (s *Shop) BeforeCreate(tx *gorm.DB) error {
@@ -26,6 +38,107 @@ func (s *Shop) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// ShopSlugRedirect - a former slug of a shop: public URLs still using it redirect to the current slug
+// Former slugs stay reserved to their shop, which alone may take them back
+type ShopSlugRedirect struct {
+	Slug      string    `gorm:"type:varchar(60);primaryKey" json:"slug"`
+	ShopID    uuid.UUID `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ========================
+// TAX RATE MODEL
+// ========================
+
+// Products reference a tax class; each shop sets the rate of its classes
+const DefaultTaxClass = "standard"
+
+type TaxRate struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ShopID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tax_rates_shop_class" json:"shop_id"`
+	Class     string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_tax_rates_shop_class" json:"class"`
+	Name      string    `json:"name"`
+	Rate      float64   `gorm:"not null" json:"rate"` // In %, e.g. 20 for 20% VAT
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (t *TaxRate) BeforeCreate(tx *gorm.DB) error {
+	t.ID = uuid.New()
+	return nil
+}
+
+// ========================
+// WHATSAPP TEMPLATE MODEL
+// ========================
+
+// WhatsAppTemplate - message pre-filled in the public WhatsApp links, for one language
+// Placeholders: see whatsapp.Placeholders
+type WhatsAppTemplate struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ShopID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_whatsapp_templates_shop_language" json:"shop_id"`
+	Language  string    `gorm:"type:varchar(35);not null;uniqueIndex:idx_whatsapp_templates_shop_language" json:"language"` // e.g. fr, ar, en, fr-MA
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (t *WhatsAppTemplate) BeforeCreate(tx *gorm.DB) error {
+	t.ID = uuid.New()
+	return nil
+}
+
+// WhatsAppClick - a customer opening a WhatsApp conversation about a product (an inquiry)
+// Logged by the public redirect; the IP is only kept as a keyed hash, to count distinct visitors
+type WhatsAppClick struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	ShopID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_whats_app_clicks_shop_created" json:"shop_id"`
+	ProductID   *uuid.UUID `gorm:"type:uuid;index" json:"product_id,omitempty"` // Cleared when the product is purged
+	ProductName string     `json:"product_name"`                                // Snapshot, kept when the product is purged
+	Language    string     `gorm:"type:varchar(35)" json:"language"`            // Template used, "" for the default message
+	Referrer    string     `gorm:"type:varchar(500)" json:"referrer"`
+	IPHash      string     `gorm:"type:varchar(64)" json:"-"`
+	CreatedAt   time.Time  `gorm:"index:idx_whats_app_clicks_shop_created" json:"created_at"`
+}
+
+func (w *WhatsAppClick) BeforeCreate(tx *gorm.DB) error {
+	w.ID = uuid.New()
+	return nil
+}
+
+// WhatsAppContact - a labelled WhatsApp number of the shop, e.g. one per department
+type WhatsAppContact struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ShopID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_whats_app_contacts_shop_label" json:"shop_id"`
+	Label     string    `gorm:"type:varchar(60);not null;uniqueIndex:idx_whats_app_contacts_shop_label" json:"label"`
+	Number    string    `gorm:"not null" json:"number"` // E.164
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (w *WhatsAppContact) BeforeCreate(tx *gorm.DB) error {
+	w.ID = uuid.New()
+	return nil
+}
+
+// WhatsAppRoute - sends the inquiries about a category (or every product) to a contact, on a schedule
+// Routes are tried by Position, the category's own first; when none is open Shop.WhatsAppNumber is used
+type WhatsAppRoute struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	ShopID    uuid.UUID        `gorm:"type:uuid;not null;index" json:"shop_id"`
+	ContactID uuid.UUID        `gorm:"type:uuid;not null;index" json:"contact_id"`
+	Contact   *WhatsAppContact `gorm:"foreignKey:ContactID" json:"-"`
+	Category  string           `json:"category"`                      // Empty = every category
+	Days      string           `gorm:"type:varchar(60)" json:"days"`  // e.g. "mon-fri,sat", empty = every day
+	Opens     string           `gorm:"type:varchar(5)" json:"opens"`  // "HH:MM", empty with Closes = all day
+	Closes    string           `gorm:"type:varchar(5)" json:"closes"` // Not after Opens = the next day
+	Position  int              `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func (w *WhatsAppRoute) BeforeCreate(tx *gorm.DB) error {
+	w.ID = uuid.New()
+	return nil
+}
+
 // ========================
 // USER MODEL
 // ========================
@@ -58,25 +171,61 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 // ========================
 
 type Product struct {
-	ID            uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	Name          string         `gorm:"not null" json:"name"`
-	Description   string         `json:"description"`
-	Category      string         `json:"category"`
-	PurchasePrice float64        `gorm:"not null" json:"purchase_price,omitempty"` // Hidden in public routes via DTO
-	SellingPrice  float64        `gorm:"not null" json:"selling_price"`
-	Stock         int            `gorm:"default:0" json:"stock"`
-	ImageURL      string         `json:"image_url"`
-	ShopID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"shop_id"`
-	Shop          Shop           `gorm:"foreignKey:ShopID" json:"-"`
-	CreatedAt     time.Time      `json:"created_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	SKU             string         `gorm:"type:varchar(64);index" json:"sku"` // Optional, unique per shop when set
+	Name            string         `gorm:"not null" json:"name"`
+	Description     string         `json:"description"`
+	Category        string         `json:"category"`
+	PurchasePrice   money.Amount   `gorm:"not null" json:"purchase_price,omitempty"` // Hidden in public routes via DTO
+	SellingPrice    money.Amount   `gorm:"not null" json:"selling_price"`
+	Stock           int            `gorm:"default:0" json:"stock"`
+	TaxClass        string         `gorm:"type:varchar(30);default:'standard'" json:"tax_class"`
+	ImageURL        string         `json:"image_url"`
+	ReorderPoint    *int           `json:"reorder_point"`                     // Low on stock at or below this; nil = shop default
+	ReorderQuantity int            `gorm:"default:0" json:"reorder_quantity"` // Usual order size; 0 = suggested from sales
+	Version         int            `gorm:"not null;default:1" json:"version"` // Incremented on every change, exposed as ETag
+	ShopID          uuid.UUID      `gorm:"type:uuid;not null;index" json:"shop_id"`
+	Shop            Shop           `gorm:"foreignKey:ShopID" json:"-"`
+	CreatedAt       time.Time      `json:"created_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
 }
 
+// ProductSearchVector - full-text document of a product for public search, weighted name > category > description
+// french_unaccent is the French configuration with accents stripped (created by config.MigrateData);
+// queries must use this exact expression to hit the idx_products_search expression index
+const ProductSearchVector = `(setweight(to_tsvector('french_unaccent', coalesce(products.name, '')), 'A') ||
+	setweight(to_tsvector('french_unaccent', coalesce(products.category, '')), 'B') ||
+	setweight(to_tsvector('french_unaccent', coalesce(products.description, '')), 'C'))`
+
 func (p *Product) BeforeCreate(tx *gorm.DB) error {
 	p.ID = uuid.New()
 	return nil
 }
 
+// EffectiveReorderPoint returns the product's reorder point, or the shop default when it has none
+func (p *Product) EffectiveReorderPoint(shopDefault int) int {
+	if p.ReorderPoint != nil {
+		return *p.ReorderPoint
+	}
+	return shopDefault
+}
+
+// ProductImage - gallery image of a product, shown after ImageURL (the main image) on the product page
+type ProductImage struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	URL       string    `gorm:"not null" json:"url"`
+	AltText   string    `json:"alt_text"`
+	Position  int       `gorm:"not null;default:0" json:"position"` // Gallery order, ascending
+	ShopID    uuid.UUID `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (i *ProductImage) BeforeCreate(tx *gorm.DB) error {
+	i.ID = uuid.New()
+	return nil
+}
+
 // ========================
 // TRANSACTION MODEL
 // ========================
@@ -90,18 +239,282 @@ const (
 )
 
 type Transaction struct {
-	ID        uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
-	Type      TransactionType `gorm:"type:varchar(20);not null" json:"type"`
-	ProductID *uuid.UUID      `gorm:"type:uuid" json:"product_id,omitempty"`
-	Product   *Product        `gorm:"foreignKey:ProductID" json:"product,omitempty"`
-	Quantity  int             `json:"quantity"`
-	Amount    float64         `gorm:"not null" json:"amount"`
-	Comment   string          `gorm:"type:text" json:"comment,omitempty"`
-	ShopID    uuid.UUID       `gorm:"type:uuid;not null;index" json:"shop_id"`
-	CreatedAt time.Time       `json:"created_at"`
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	Type           TransactionType `gorm:"type:varchar(20);not null" json:"type"`
+	ProductID      *uuid.UUID      `gorm:"type:uuid" json:"product_id,omitempty"`
+	Product        *Product        `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	ProductName    string          `json:"product_name,omitempty"` // Sale: snapshot, kept when the product is purged
+	Quantity       int             `json:"quantity"`
+	UnitPrice      money.Amount    `json:"unit_price,omitempty"`                          // Sale: product price at the time of sale
+	Discount       money.Amount    `json:"discount,omitempty"`                            // Sale: total discount granted
+	DiscountReason string          `gorm:"type:text" json:"discount_reason,omitempty"`    // Mandatory whenever Discount > 0
+	PriceOverride  bool            `gorm:"default:false" json:"price_override,omitempty"` // Sold below cost with SuperAdmin approval
+	PromotionID    *uuid.UUID      `gorm:"type:uuid" json:"promotion_id,omitempty"`
+	CouponCode     string          `json:"coupon_code,omitempty"`
+	Amount         money.Amount    `gorm:"not null" json:"amount"` // Tax-inclusive
+	NetAmount      money.Amount    `json:"net_amount"`             // Excluding tax
+	TaxAmount      money.Amount    `json:"tax_amount"`
+	TaxRate        float64         `json:"tax_rate"` // In %, snapshot at the time of the transaction
+	Comment        string          `gorm:"type:text" json:"comment,omitempty"`
+	ShopID         uuid.UUID       `gorm:"type:uuid;not null;index;uniqueIndex:idx_transactions_shop_invoice" json:"shop_id"`
+	InvoiceNumber  *int64          `gorm:"uniqueIndex:idx_transactions_shop_invoice" json:"invoice_number,omitempty"` // Sale only, sequential per shop
+	Payments       []Payment       `gorm:"foreignKey:TransactionID" json:"payments,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
 
 func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 	t.ID = uuid.New()
 	return nil
 }
+
+// InvoiceCounter - last invoice number issued by a shop
+// Incremented in the same SQL transaction as the sale, so numbers have no gaps
+type InvoiceCounter struct {
+	ShopID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"shop_id"`
+	LastNumber int64     `gorm:"not null;default:0" json:"last_number"`
+}
+
+// ========================
+// PAYMENT MODEL
+// ========================
+
+type PaymentMethod string
+
+const (
+	PaymentCash         PaymentMethod = "Cash"
+	PaymentCard         PaymentMethod = "Card"
+	PaymentBankTransfer PaymentMethod = "BankTransfer"
+	PaymentMobileMoney  PaymentMethod = "MobileMoney"
+)
+
+// Payment - one tender of a transaction (a sale can be split across methods)
+type Payment struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID uuid.UUID     `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Method        PaymentMethod `gorm:"type:varchar(20);not null" json:"method"`
+	Amount        money.Amount  `gorm:"not null" json:"amount"`
+	ShopID        uuid.UUID     `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	p.ID = uuid.New()
+	return nil
+}
+
+// ========================
+// PROMOTION MODEL
+// ========================
+
+type PromotionType string
+
+const (
+	PromotionPercentOff PromotionType = "PercentOff" // Value % off the line
+	PromotionFixedOff   PromotionType = "FixedOff"   // Value off each unit
+	PromotionBuyXGetY   PromotionType = "BuyXGetY"   // Every BuyQuantity+GetQuantity units, GetQuantity are free
+)
+
+// Promotion - a shop-wide, category-wide or single-product discount rule
+// Promotions without CouponCode apply automatically; the others need the code at checkout
+type Promotion struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	Name        string        `gorm:"not null" json:"name"`
+	Type        PromotionType `gorm:"type:varchar(20);not null" json:"type"`
+	Value       float64       `json:"value,omitempty"` // PercentOff: percentage, FixedOff: amount off each unit
+	BuyQuantity int           `json:"buy_quantity,omitempty"`
+	GetQuantity int           `json:"get_quantity,omitempty"`
+	Category    string        `json:"category,omitempty"` // Empty = every category
+	ProductID   *uuid.UUID    `gorm:"type:uuid" json:"product_id,omitempty"`
+	CouponCode  string        `gorm:"index" json:"coupon_code,omitempty"` // Stored upper-case
+	StartsAt    *time.Time    `json:"starts_at,omitempty"`
+	EndsAt      *time.Time    `json:"ends_at,omitempty"`
+	MaxUses     int           `gorm:"default:0" json:"max_uses"` // 0 = unlimited
+	UsesCount   int           `gorm:"default:0" json:"uses_count"`
+	Active      bool          `gorm:"default:true" json:"active"`
+	ShopID      uuid.UUID     `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func (p *Promotion) BeforeCreate(tx *gorm.DB) error {
+	p.ID = uuid.New()
+	return nil
+}
+
+// AppliesTo reports whether the promotion targets the product and is running at the given time
+func (p *Promotion) AppliesTo(product Product, at time.Time) bool {
+	if !p.Active || p.ShopID != product.ShopID {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && at.After(*p.EndsAt) {
+		return false
+	}
+	if p.MaxUses > 0 && p.UsesCount >= p.MaxUses {
+		return false
+	}
+	if p.ProductID != nil && *p.ProductID != product.ID {
+		return false
+	}
+	if p.Category != "" && p.Category != product.Category {
+		return false
+	}
+	return true
+}
+
+// ========================
+// RESERVATION MODEL
+// ========================
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "Active"
+	ReservationCompleted ReservationStatus = "Completed" // Picked up: converted to a Sale
+	ReservationCancelled ReservationStatus = "Cancelled"
+	ReservationExpired   ReservationStatus = "Expired"
+)
+
+// Reservation - quantity held for a customer until pickup or expiry
+// Physical stock only decreases on pickup; until then the held quantity is just not for sale
+type Reservation struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID     uuid.UUID         `gorm:"type:uuid;not null;index" json:"product_id"`
+	Product       *Product          `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Quantity      int               `gorm:"not null" json:"quantity"`
+	CustomerName  string            `gorm:"not null" json:"customer_name"`
+	CustomerPhone string            `json:"customer_phone"`
+	Note          string            `gorm:"type:text" json:"note,omitempty"`
+	Status        ReservationStatus `gorm:"type:varchar(20);not null;default:'Active';index" json:"status"`
+	ExpiresAt     time.Time         `gorm:"not null;index" json:"expires_at"`
+	TransactionID *uuid.UUID        `gorm:"type:uuid" json:"transaction_id,omitempty"` // The Sale created on pickup
+	ShopID        uuid.UUID         `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+func (r *Reservation) BeforeCreate(tx *gorm.DB) error {
+	r.ID = uuid.New()
+	return nil
+}
+
+// Holds reports whether the reservation still holds stock at the given time
+// Expired reservations stop holding stock right away, before the expiry worker marks them
+func (r *Reservation) Holds(at time.Time) bool {
+	return r.Status == ReservationActive && at.Before(r.ExpiresAt)
+}
+
+// ========================
+// NOTIFICATION MODELS
+// ========================
+
+// NotificationSubscription - where a shop wants its stock alerts delivered
+// Target depends on the channel: e-mail address, webhook URL, WhatsApp number (log: optional label)
+type NotificationSubscription struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Channel        string     `gorm:"type:varchar(20);not null" json:"channel"`
+	Target         string     `gorm:"not null;default:''" json:"target"`
+	Enabled        bool       `gorm:"not null;default:true" json:"enabled"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty"`
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"` // Error of the last delivery, empty when it succeeded
+	ShopID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (n *NotificationSubscription) BeforeCreate(tx *gorm.DB) error {
+	n.ID = uuid.New()
+	return nil
+}
+
+// StockAlert - a low stock alert already sent for a product
+// While the row exists the product is not alerted again; it is removed once stock goes back above the threshold
+type StockAlert struct {
+	ProductID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"product_id"`
+	ShopID       uuid.UUID `gorm:"type:uuid;not null;index" json:"shop_id"`
+	Stock        int       `json:"stock"`
+	ReorderPoint int       `json:"reorder_point"`
+	AlertedAt    time.Time `gorm:"autoCreateTime" json:"alerted_at"`
+}
+
+// ========================
+// WEBHOOK MODELS
+// ========================
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	WebhookFailed    WebhookDeliveryStatus = "failed" // Gave up after the last retry
+)
+
+// WebhookEndpoint - a URL of the shop that receives signed event payloads
+type WebhookEndpoint struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	URL         string    `gorm:"not null" json:"url"`
+	Secret      string    `gorm:"not null" json:"-"`           // HMAC-SHA256 key of the signatures
+	Events      string    `gorm:"type:text;not null" json:"-"` // Comma-separated event names, "*" for all
+	Description string    `json:"description,omitempty"`
+	Active      bool      `gorm:"not null;default:true" json:"active"`
+	ShopID      uuid.UUID `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (w *WebhookEndpoint) BeforeCreate(tx *gorm.DB) error {
+	w.ID = uuid.New()
+	return nil
+}
+
+// WebhookDelivery - one event to deliver to one endpoint (the outbox)
+// Written in the same DB transaction as the change it describes, then sent by a background worker
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primaryKey" json:"id"`
+	EndpointID     uuid.UUID             `gorm:"type:uuid;not null;index" json:"endpoint_id"`
+	EventID        uuid.UUID             `gorm:"type:uuid;not null" json:"event_id"` // Same for every endpoint and redelivery of an event
+	Event          string                `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        string                `gorm:"type:text;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"not null" json:"next_attempt_at"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"`
+	LastError      string                `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	ShopID         uuid.UUID             `gorm:"type:uuid;not null;index" json:"shop_id"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	Logs           []WebhookAttempt      `gorm:"foreignKey:DeliveryID" json:"logs,omitempty"`
+}
+
+func (w *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	w.ID = uuid.New()
+	return nil
+}
+
+// WebhookAttempt - log of one HTTP attempt of a delivery
+type WebhookAttempt struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	DeliveryID uuid.UUID `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `gorm:"type:text" json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (w *WebhookAttempt) BeforeCreate(tx *gorm.DB) error {
+	w.ID = uuid.New()
+	return nil
+}
+
+// ========================
+// DATA MIGRATION MODEL
+// ========================
+
+// DataMigration - a one-off data migration that has run, so it is not repeated on the next boot
+type DataMigration struct {
+	Name      string    `gorm:"type:varchar(100);primaryKey" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
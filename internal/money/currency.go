@@ -0,0 +1,100 @@
+package money
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Currency - display rules of an ISO 4217 currency
+type Currency struct {
+	Code     string
+	Symbol   string
+	Decimals int  // Decimals shown when formatting (at most 2, the storage precision)
+	Suffix   bool // Symbol after the amount ("12,50 DH") rather than before ("$12.50")
+}
+
+// currencies supported by the shops; all of them have at most two decimals
+var currencies = map[string]Currency{
+	"MAD": {Code: "MAD", Symbol: "DH", Decimals: 2, Suffix: true},
+	"EUR": {Code: "EUR", Symbol: "€", Decimals: 2, Suffix: true},
+	"USD": {Code: "USD", Symbol: "$", Decimals: 2},
+	"GBP": {Code: "GBP", Symbol: "£", Decimals: 2},
+	"DZD": {Code: "DZD", Symbol: "DA", Decimals: 2, Suffix: true},
+	"XOF": {Code: "XOF", Symbol: "FCFA", Decimals: 0, Suffix: true},
+	"XAF": {Code: "XAF", Symbol: "FCFA", Decimals: 0, Suffix: true},
+}
+
+// LookupCurrency returns the currency for an ISO code (case-insensitive)
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// CurrencyOrDefault returns the currency for code, or the default currency if unknown
+func CurrencyOrDefault(code string) Currency {
+	if c, ok := LookupCurrency(code); ok {
+		return c
+	}
+	return currencies[DefaultCurrency]
+}
+
+// Round rounds the amount to the decimals the currency actually uses (e.g. whole francs CFA)
+func (c Currency) Round(a Amount) Amount {
+	step := Amount(1)
+	for i := c.Decimals; i < 2; i++ {
+		step *= 10
+	}
+	if step == 1 {
+		return a
+	}
+	half := step / 2
+	if a < 0 {
+		return -((-a + half) / step * step)
+	}
+	return (a + half) / step * step
+}
+
+// Format renders an amount for humans, e.g. "11 999,00 DH" or "$11,999.00"
+// Currencies shown with a suffix symbol use the French convention (space thousands, comma decimals)
+func (c Currency) Format(a Amount) string {
+	a = c.Round(a)
+
+	sign := ""
+	if a < 0 {
+		sign = "-"
+		a = -a
+	}
+
+	thousandsSep, decimalSep := ",", "."
+	if c.Suffix {
+		thousandsSep, decimalSep = " ", ","
+	}
+
+	units := groupThousands(int64(a)/Scale, thousandsSep)
+	if c.Decimals > 0 {
+		frac := a.String()
+		units += decimalSep + frac[len(frac)-2:][:c.Decimals]
+	}
+
+	if c.Suffix {
+		return sign + units + " " + c.Symbol
+	}
+	return sign + c.Symbol + units
+}
+
+// Format renders an amount in the given currency code (default currency if unknown)
+func Format(a Amount, code string) string {
+	return CurrencyOrDefault(code).Format(a)
+}
+
+func groupThousands(v int64, sep string) string {
+	s := strings.Builder{}
+	digits := strconv.FormatInt(v, 10)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			s.WriteString(sep)
+		}
+		s.WriteRune(d)
+	}
+	return s.String()
+}
@@ -0,0 +1,163 @@
+// Package money handles monetary values without floating-point errors.
+//
+// Amounts are stored as integers counting hundredths of the currency unit
+// (centimes, cents). JSON keeps the decimal form used by the API since the
+// beginning ("selling_price": 11999.99), so clients are not affected.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of stored units per currency unit
+const Scale = 100
+
+// DefaultCurrency is used for shops created before currencies were configurable
+const DefaultCurrency = "MAD"
+
+// Amount - a monetary value in hundredths of the currency unit
+type Amount int64
+
+// ErrInvalidAmount is returned when a decimal string cannot be parsed
+var ErrInvalidAmount = errors.New("invalid monetary amount")
+
+// FromFloat converts a decimal value, rounding half away from zero to the nearest hundredth
+// Only for input that is already a float (percent computations, legacy data)
+func FromFloat(v float64) Amount {
+	return Amount(math.Round(v * Scale))
+}
+
+// FromUnits builds an amount from whole currency units
+func FromUnits(units int64) Amount {
+	return Amount(units * Scale)
+}
+
+// Parse reads a decimal string such as "1299.99", "-3.5" or "12"
+// More than two decimals are rounded half away from zero
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidAmount
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || units > math.MaxInt64/Scale-1 {
+		return 0, ErrInvalidAmount
+	}
+
+	// Keep two decimals, round on the third
+	frac := (fracPart + "000")[:3]
+	hundredths, _ := strconv.ParseInt(frac[:2], 10, 64)
+	if frac[2] >= '5' {
+		hundredths++
+	}
+
+	a := Amount(units*Scale + hundredths)
+	if neg {
+		a = -a
+	}
+	return a, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Float returns the amount as a decimal value, for display or ratio computations only
+func (a Amount) Float() float64 {
+	return float64(a) / Scale
+}
+
+// Mul multiplies by a quantity
+func (a Amount) Mul(qty int) Amount {
+	return a * Amount(qty)
+}
+
+// Percent returns p% of the amount, rounded to the nearest hundredth
+func (a Amount) Percent(p float64) Amount {
+	return Amount(math.Round(float64(a) * p / 100))
+}
+
+// RemoveTax splits a tax-inclusive amount into its net part, for a rate in %
+// The tax is the difference so that net + tax always equals the original amount
+func (a Amount) RemoveTax(rate float64) Amount {
+	return Amount(math.Round(float64(a) * 100 / (100 + rate)))
+}
+
+// Min returns the smaller of two amounts
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// String renders the amount with two decimals, e.g. "1299.99"
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/Scale, v%Scale)
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number (or numeric string) without going through float64
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	// Accept exponent forms sent by some clients (e.g. 1e3)
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return ErrInvalidAmount
+		}
+		*a = FromFloat(f)
+		return nil
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
@@ -0,0 +1,239 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "1299.99", want: 129999},
+		{in: "12", want: 1200},
+		{in: "-3.5", want: -350},
+		{in: "+7", want: 700},
+		{in: " 42.10 ", want: 4210},
+		{in: ".5", want: 50},
+		{in: "5.", want: 500},
+		{in: "0.05", want: 5},
+		{in: "1.004", want: 100},
+		{in: "1.005", want: 101}, // Rounded on the third decimal, half away from zero
+		{in: "0.999", want: 100},
+		{in: "-0.005", want: -1},
+		{in: "92233720368547757", want: 9223372036854775700},
+		{in: "92233720368547758", wantErr: true}, // Would overflow int64 hundredths
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidAmount) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, ErrInvalidAmount)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+// Legacy float columns are converted with ROUND(x::numeric * 100): the float's shortest decimal
+// form, rounded half away from zero. Parse of that form must give the same hundredths, where
+// FromFloat(x) would be off by one on values like 1.005 (100.49999… once multiplied)
+func TestParseMatchesLegacyConversion(t *testing.T) {
+	tests := []struct {
+		legacy float64
+		want   Amount
+	}{
+		{1299.99, 129999},
+		{0.1 + 0.2, 30},
+		{1.005, 101},
+		{2.675, 268},
+		{-2.675, -268},
+		{11999.999, 1200000},
+		{1e6, 100000000},
+	}
+	for _, tt := range tests {
+		text := strconv.FormatFloat(tt.legacy, 'f', -1, 64)
+		got, err := Parse(text)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %d, %v, want %d", text, got, err, tt.want)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{129999, "1299.99"},
+		{1200, "12.00"},
+		{5, "0.05"},
+		{0, "0.00"},
+		{-350, "-3.50"},
+		{-5, "-0.05"},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.in)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("Marshal(%d) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: `1299.99`, want: 129999},
+		{in: `"1299.99"`, want: 129999},
+		{in: `12`, want: 1200},
+		{in: `-0.5`, want: -50},
+		{in: `1e3`, want: 100000},
+		{in: `1.5E2`, want: 15000},
+		{in: `null`, want: 777}, // Left untouched
+		{in: `"abc"`, wantErr: true},
+		{in: `true`, wantErr: true},
+		{in: `"1e"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got := Amount(777)
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %d, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type product struct {
+		SellingPrice Amount  `json:"selling_price"`
+		Discount     *Amount `json:"discount"`
+	}
+	discount := Amount(-150)
+	in := product{SellingPrice: 1199999, Discount: &discount}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"selling_price":11999.99,"discount":-1.50}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	var out product
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.SellingPrice != in.SellingPrice || out.Discount == nil || *out.Discount != discount {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		p      float64
+		want   Amount
+	}{
+		{1000, 15, 150},
+		{333, 50, 167}, // 166.5 rounds half away from zero
+		{-333, 50, -167},
+		{1, 50, 1},
+		{1, 49, 0},
+		{999, 33.333, 333},
+		{129999, 0, 0},
+		{129999, 100, 129999},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.Percent(tt.p); got != tt.want {
+			t.Errorf("Amount(%d).Percent(%v) = %d, want %d", tt.amount, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveTax(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		rate   float64
+		want   Amount
+	}{
+		{12000, 20, 10000},
+		{9999, 20, 8333}, // 8332.5
+		{10000, 0, 10000},
+		{11000, 10, 10000},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.RemoveTax(tt.rate); got != tt.want {
+			t.Errorf("Amount(%d).RemoveTax(%v) = %d, want %d", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestCurrencyRound(t *testing.T) {
+	mad, _ := LookupCurrency("MAD")
+	xof, _ := LookupCurrency("xof")
+
+	tests := []struct {
+		currency Currency
+		in       Amount
+		want     Amount
+	}{
+		{mad, 12345, 12345},
+		{mad, -12345, -12345},
+		{xof, 12345, 12300},
+		{xof, 12350, 12400}, // Half a franc rounds away from zero
+		{xof, 12349, 12300},
+		{xof, -12350, -12400},
+		{xof, -12349, -12300},
+		{xof, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.currency.Round(tt.in); got != tt.want {
+			t.Errorf("%s.Round(%d) = %d, want %d", tt.currency.Code, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		code   string
+		want   string
+	}{
+		{1199900, "MAD", "11 999,00 DH"},
+		{1199900, "USD", "$11,999.00"},
+		{-150, "USD", "-$1.50"},
+		{50, "EUR", "0,50 €"},
+		{12350, "XOF", "124 FCFA"},
+		{123456789, "GBP", "£1,234,567.89"},
+		{1000, "???", "10,00 DH"}, // Unknown code: default currency
+	}
+	for _, tt := range tests {
+		if got := Format(tt.amount, tt.code); got != tt.want {
+			t.Errorf("Format(%d, %q) = %q, want %q", tt.amount, tt.code, got, tt.want)
+		}
+	}
+}
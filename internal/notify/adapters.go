@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"electronic-shop/internal/webhooks"
+)
+
+// ========================
+// LOG / FILE
+// ========================
+
+// LogNotifier writes alerts as JSON lines to a file, or to the server log when Path is empty
+// Meant for development and tests: nothing leaves the machine
+type LogNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, target string, a Alert) error {
+	line, err := json.Marshal(struct {
+		Target string `json:"target,omitempty"`
+		Text   string `json:"text"`
+		Alert
+	}{target, a.Text(), a})
+	if err != nil {
+		return err
+	}
+
+	if n.Path == "" {
+		log.Printf("notify: %s", line)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// ========================
+// EMAIL (SMTP)
+// ========================
+
+// SMTPNotifier sends a plain text e-mail; STARTTLS is used when the server offers it
+type SMTPNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, target string, a Alert) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", target)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", a.Subject()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.At.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(a.Text() + "\r\n")
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+
+	// net/smtp has no context support: bound the whole exchange instead
+	done := make(chan error, 1)
+	go func() {
+		addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+		done <- smtp.SendMail(addr, auth, n.From, []string{target}, msg.Bytes())
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ========================
+// WEBHOOK
+// ========================
+
+// WebhookNotifier POSTs the alert as JSON to the subscribed URL
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+// NewWebhookNotifier - targets are chosen by shops, so the client only connects to public addresses
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{Client: webhooks.NewClient(10 * time.Second)}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, target string, a Alert) error {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+		Alert
+	}{a.Text(), a})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.Client, target, "", body)
+}
+
+// ========================
+// WHATSAPP BUSINESS (Cloud API)
+// ========================
+
+const whatsAppAPIURL = "https://graph.facebook.com/v19.0"
+
+// WhatsAppNotifier sends a message through the WhatsApp Business Cloud API
+// Free text is only delivered within 24h of the recipient's last message; outside that window
+// WhatsApp requires an approved template, set with Template (body parameters: text of the alert)
+type WhatsAppNotifier struct {
+	Client        *http.Client
+	Token         string
+	PhoneNumberID string
+	Template      string
+	BaseURL       string
+}
+
+func NewWhatsAppNotifier(token, phoneNumberID, template string) *WhatsAppNotifier {
+	return &WhatsAppNotifier{
+		Client:        &http.Client{Timeout: 10 * time.Second},
+		Token:         token,
+		PhoneNumberID: phoneNumberID,
+		Template:      template,
+		BaseURL:       whatsAppAPIURL,
+	}
+}
+
+func (n *WhatsAppNotifier) Notify(ctx context.Context, target string, a Alert) error {
+	msg := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                strings.TrimPrefix(target, "+"),
+	}
+	if n.Template != "" {
+		msg["type"] = "template"
+		msg["template"] = map[string]interface{}{
+			"name":     n.Template,
+			"language": map[string]string{"code": "fr"},
+			"components": []map[string]interface{}{{
+				"type":       "body",
+				"parameters": []map[string]string{{"type": "text", "text": a.Text()}},
+			}},
+		}
+	} else {
+		msg["type"] = "text"
+		msg["text"] = map[string]string{"body": a.Text()}
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.Client, n.BaseURL+"/"+n.PhoneNumberID+"/messages", "Bearer "+n.Token, body)
+}
+
+// postJSON sends body and treats any non-2xx answer as a failure
+func postJSON(ctx context.Context, client *http.Client, url, authorization string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "electronic-shop-notifier")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// The answer is not kept: the error ends up in last_error, shown to the shop
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package notify delivers shop alerts (low stock...) through pluggable channels.
+//
+// Each channel is a Notifier; shops subscribe a target (address, URL, phone) per channel.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channels a shop can subscribe to
+const (
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+	ChannelWhatsApp = "whatsapp"
+	ChannelLog      = "log"
+)
+
+// EventStockLow is the kind of the alert sent when a sale leaves a product at or below its reorder point
+const EventStockLow = "stock.low"
+
+var ErrChannelDisabled = errors.New("notification channel is not configured on this server")
+
+// Alert - what happened, with enough context for every channel to render it
+type Alert struct {
+	Event        string    `json:"event"`
+	ShopID       uuid.UUID `json:"shop_id"`
+	ShopName     string    `json:"shop_name"`
+	ProductID    uuid.UUID `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	SKU          string    `json:"sku,omitempty"`
+	Stock        int       `json:"stock"`
+	ReorderPoint int       `json:"reorder_point"`
+	At           time.Time `json:"at"`
+}
+
+// Subject is a one-line summary, used as e-mail subject
+func (a Alert) Subject() string {
+	return fmt.Sprintf("[%s] Stock faible : %s", a.ShopName, a.ProductName)
+}
+
+// Text is the human-readable message
+func (a Alert) Text() string {
+	name := a.ProductName
+	if a.SKU != "" {
+		name += " (" + a.SKU + ")"
+	}
+	if a.Stock <= 0 {
+		return fmt.Sprintf("%s : %s est en rupture de stock.", a.ShopName, name)
+	}
+	return fmt.Sprintf("%s : il ne reste que %d %s (seuil de réapprovisionnement : %d).",
+		a.ShopName, a.Stock, name, a.ReorderPoint)
+}
+
+// Notifier delivers an alert to one target of its channel
+type Notifier interface {
+	Notify(ctx context.Context, target string, a Alert) error
+}
+
+// Dispatcher routes alerts to the notifier of each channel
+// Channels missing from the map are not configured and refuse deliveries
+type Dispatcher map[string]Notifier
+
+func (d Dispatcher) Notify(ctx context.Context, channel, target string, a Alert) error {
+	n, ok := d[channel]
+	if !ok {
+		return ErrChannelDisabled
+	}
+	return n.Notify(ctx, target, a)
+}
+
+// FromEnv builds the dispatcher from the environment
+// The log channel is always available; the others only when their settings are present
+func FromEnv() Dispatcher {
+	d := Dispatcher{ChannelLog: &LogNotifier{Path: os.Getenv("NOTIFY_LOG_FILE")}}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if port == 0 {
+			port = 587
+		}
+		d[ChannelEmail] = &SMTPNotifier{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}
+	}
+
+	d[ChannelWebhook] = NewWebhookNotifier()
+
+	if token := os.Getenv("WHATSAPP_API_TOKEN"); token != "" {
+		d[ChannelWhatsApp] = NewWhatsAppNotifier(token, os.Getenv("WHATSAPP_PHONE_NUMBER_ID"), os.Getenv("WHATSAPP_ALERT_TEMPLATE"))
+	}
+
+	for channel := range d {
+		log.Printf("notify: %s channel enabled", channel)
+	}
+	return d
+}
@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"electronic-shop/internal/models"
+	"electronic-shop/internal/webhooks"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockAlerter sends low stock alerts to the shop's subscriptions
+// A product is alerted once when it reaches its reorder point, and again only after
+// its stock went back above it (restock) and down again
+type StockAlerter struct {
+	db         *gorm.DB
+	dispatcher Dispatcher
+}
+
+func NewStockAlerter(db *gorm.DB, dispatcher Dispatcher) *StockAlerter {
+	return &StockAlerter{db: db, dispatcher: dispatcher}
+}
+
+// Check compares the stock of the given products to their reorder point, in the background
+// Call it after the stock changes have been committed
+func (a *StockAlerter) Check(shopID uuid.UUID, productIDs ...uuid.UUID) {
+	if a == nil || len(productIDs) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := a.check(ctx, shopID, productIDs); err != nil {
+			log.Printf("notify: stock alerts of shop %s: %v", shopID, err)
+		}
+	}()
+}
+
+func (a *StockAlerter) check(ctx context.Context, shopID uuid.UUID, productIDs []uuid.UUID) error {
+	db := a.db.WithContext(ctx)
+
+	var shop models.Shop
+	if err := db.First(&shop, "id = ?", shopID).Error; err != nil {
+		return err
+	}
+
+	var products []models.Product
+	if err := db.Where("shop_id = ? AND id IN ?", shopID, productIDs).Find(&products).Error; err != nil {
+		return err
+	}
+
+	for _, p := range products {
+		threshold := p.EffectiveReorderPoint(shop.DefaultReorderPoint)
+		if p.Stock > threshold {
+			// Back above the threshold: the next drop alerts again
+			if err := db.Where("product_id = ?", p.ID).Delete(&models.StockAlert{}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		alert := Alert{
+			Event:        EventStockLow,
+			ShopID:       shop.ID,
+			ShopName:     shop.Name,
+			ProductID:    p.ID,
+			ProductName:  p.Name,
+			SKU:          p.SKU,
+			Stock:        p.Stock,
+			ReorderPoint: threshold,
+			At:           time.Now(),
+		}
+
+		// The insert is the de-duplication: of concurrent checks, only the one that creates the row sends
+		created := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.StockAlert{
+				ProductID:    p.ID,
+				ShopID:       shopID,
+				Stock:        p.Stock,
+				ReorderPoint: threshold,
+			})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			created = true
+			return webhooks.Enqueue(tx, shopID, webhooks.EventStockLow, alert)
+		})
+		if err != nil {
+			return err
+		}
+		if created {
+			a.broadcast(ctx, shop, alert)
+		}
+	}
+	return nil
+}
+
+// broadcast sends the alert to every enabled subscription of the shop
+func (a *StockAlerter) broadcast(ctx context.Context, shop models.Shop, alert Alert) {
+	var subscriptions []models.NotificationSubscription
+	if err := a.db.WithContext(ctx).
+		Where("shop_id = ? AND enabled = ?", shop.ID, true).
+		Find(&subscriptions).Error; err != nil {
+		log.Printf("notify: subscriptions of shop %s: %v", shop.ID, err)
+		return
+	}
+
+	for _, sub := range subscriptions {
+		if err := a.Send(ctx, sub, alert); err != nil {
+			log.Printf("notify: %s to %q: %v", sub.Channel, sub.Target, err)
+		}
+	}
+}
+
+// Send delivers one alert to one subscription and records the outcome on it
+func (a *StockAlerter) Send(ctx context.Context, sub models.NotificationSubscription, alert Alert) error {
+	err := a.dispatcher.Notify(ctx, sub.Channel, sub.Target, alert)
+
+	updates := map[string]interface{}{"last_error": ""}
+	if err != nil {
+		updates["last_error"] = err.Error()
+	} else {
+		updates["last_notified_at"] = time.Now()
+	}
+	a.db.WithContext(ctx).Model(&models.NotificationSubscription{}).Where("id = ?", sub.ID).Updates(updates)
+
+	return err
+}
+
+// Channels returns the channels configured on this server
+func (a *StockAlerter) Channels() []string {
+	channels := []string{}
+	for _, c := range []string{ChannelEmail, ChannelWebhook, ChannelWhatsApp, ChannelLog} {
+		if _, ok := a.dispatcher[c]; ok {
+			channels = append(channels, c)
+		}
+	}
+	return channels
+}
@@ -0,0 +1,137 @@
+// Package phone parses the phone numbers of shops and normalizes them to E.164 ("+212600000000"),
+// the international format WhatsApp links need.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCountry - country of the numbers of shops that did not choose one
+const DefaultCountry = "MA"
+
+// maxDigits - longest number allowed by E.164, calling code included
+const maxDigits = 15
+
+// Country - dialing rules of an ISO 3166 country
+type Country struct {
+	Code        string
+	CallingCode string
+	Trunk       string // Dialed before national numbers ("0" in "06 00 00 00 00"), "" when the country has none
+	MinLength   int    // Digits of a national number, without trunk prefix
+	MaxLength   int
+}
+
+// countries the shops may choose; numbers of other countries are accepted in international format
+var countries = map[string]Country{
+	"MA": {Code: "MA", CallingCode: "212", Trunk: "0", MinLength: 9, MaxLength: 9},
+	"DZ": {Code: "DZ", CallingCode: "213", Trunk: "0", MinLength: 8, MaxLength: 9},
+	"TN": {Code: "TN", CallingCode: "216", MinLength: 8, MaxLength: 8},
+	"EG": {Code: "EG", CallingCode: "20", Trunk: "0", MinLength: 8, MaxLength: 10},
+	"SN": {Code: "SN", CallingCode: "221", MinLength: 9, MaxLength: 9},
+	"CI": {Code: "CI", CallingCode: "225", MinLength: 10, MaxLength: 10},
+	"CM": {Code: "CM", CallingCode: "237", MinLength: 9, MaxLength: 9},
+	"FR": {Code: "FR", CallingCode: "33", Trunk: "0", MinLength: 9, MaxLength: 9},
+	"BE": {Code: "BE", CallingCode: "32", Trunk: "0", MinLength: 8, MaxLength: 9},
+	"ES": {Code: "ES", CallingCode: "34", MinLength: 9, MaxLength: 9},
+	"NL": {Code: "NL", CallingCode: "31", Trunk: "0", MinLength: 9, MaxLength: 9},
+	"DE": {Code: "DE", CallingCode: "49", Trunk: "0", MinLength: 6, MaxLength: 13},
+	"GB": {Code: "GB", CallingCode: "44", Trunk: "0", MinLength: 9, MaxLength: 10},
+	"AE": {Code: "AE", CallingCode: "971", Trunk: "0", MinLength: 8, MaxLength: 9},
+	"SA": {Code: "SA", CallingCode: "966", Trunk: "0", MinLength: 8, MaxLength: 9},
+	"US": {Code: "US", CallingCode: "1", MinLength: 10, MaxLength: 10},
+	"CA": {Code: "CA", CallingCode: "1", MinLength: 10, MaxLength: 10},
+}
+
+var (
+	ErrEmpty      = errors.New("phone number is required")
+	ErrCharacters = errors.New("phone number may only contain digits, spaces, dashes, dots, parentheses and a leading +")
+	ErrLength     = errors.New("phone number does not have the right number of digits for its country")
+	ErrCountry    = errors.New("unsupported country, use an ISO 3166 code such as MA, FR or SN")
+)
+
+// LookupCountry returns the country for an ISO code (case-insensitive)
+func LookupCountry(code string) (Country, bool) {
+	c, ok := countries[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// byCallingCode returns the rules of the calling code an international number starts with
+// Calling codes are prefix-free: countries matching the same number share its code and rules (US, CA)
+func byCallingCode(digits string) (Country, bool) {
+	for _, c := range countries {
+		if strings.HasPrefix(digits, c.CallingCode) {
+			return c, true
+		}
+	}
+	return Country{}, false
+}
+
+func (c Country) validLength(n int) bool {
+	return n >= c.MinLength && n <= c.MaxLength
+}
+
+// Normalize parses a number as typed by a person and returns it in E.164
+// Numbers without "+" or "00" are national numbers of defaultCountry, with or without trunk prefix;
+// digits already starting with its calling code (the former "212600000000" format) are international.
+func Normalize(raw, defaultCountry string) (string, error) {
+	country, ok := LookupCountry(defaultCountry)
+	if !ok {
+		return "", ErrCountry
+	}
+
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", ErrEmpty
+	}
+	// "+33 (0)6 …": the trunk prefix written for national callers
+	s = strings.ReplaceAll(s, "(0)", "")
+	international := strings.HasPrefix(s, "+")
+	s = strings.TrimPrefix(s, "+")
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune(" -./()\u00a0", r):
+		default:
+			return "", ErrCharacters
+		}
+	}
+	digits := b.String()
+	if digits == "" {
+		return "", ErrEmpty
+	}
+
+	if !international && strings.HasPrefix(digits, "00") {
+		international, digits = true, digits[2:]
+	}
+	if !international {
+		switch {
+		case country.Trunk != "" && strings.HasPrefix(digits, country.Trunk):
+			digits = country.CallingCode + digits[len(country.Trunk):]
+		case strings.HasPrefix(digits, country.CallingCode) && country.validLength(len(digits)-len(country.CallingCode)):
+		default:
+			digits = country.CallingCode + digits
+		}
+	}
+
+	if digits == "" || digits[0] == '0' || len(digits) > maxDigits {
+		return "", ErrLength
+	}
+	if rules, ok := byCallingCode(digits); ok {
+		national := digits[len(rules.CallingCode):]
+		// "+212 06…": trunk prefix kept after the calling code
+		if rules.Trunk != "" && strings.HasPrefix(national, rules.Trunk) && !rules.validLength(len(national)) {
+			national = national[len(rules.Trunk):]
+		}
+		if !rules.validLength(len(national)) {
+			return "", ErrLength
+		}
+		digits = rules.CallingCode + national
+	} else if len(digits) < 8 {
+		return "", ErrLength
+	}
+	return "+" + digits, nil
+}
@@ -0,0 +1,112 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw     string
+		country string
+		want    string
+	}{
+		// National numbers, with or without trunk prefix
+		{"06 00 00 00 00", "MA", "+212600000000"},
+		{"0600-000-000", "MA", "+212600000000"},
+		{"600000000", "MA", "+212600000000"},
+		{"0600.00.00.00", "ma", "+212600000000"},
+		{"06 12 34 56 78", "FR", "+33612345678"},
+		{"0551 23 45 67", "DZ", "+213551234567"},
+		{"0470 12 34 56", "BE", "+32470123456"},
+		{"020 7946 0958", "GB", "+442079460958"},
+		{"050 123 4567", "AE", "+971501234567"},
+		{"(415) 555-2671", "US", "+14155552671"},
+		// Countries without trunk prefix
+		{"20 123 456", "TN", "+21620123456"},
+		{"77 123 45 67", "SN", "+221771234567"},
+		{"612 34 56 78", "ES", "+34612345678"},
+		// Former format: calling code without "+"
+		{"212600000000", "MA", "+212600000000"},
+		// International numbers, whatever the shop's country
+		{"+212 6 00 00 00 00", "FR", "+212600000000"},
+		{"+33 6 12 34 56 78", "MA", "+33612345678"},
+		{"+33 (0)6 12 34 56 78", "MA", "+33612345678"},
+		{"+212 (0)6 00 00 00 00", "SN", "+212600000000"},
+		{"+212 0600000000", "MA", "+212600000000"}, // Trunk prefix kept after the calling code
+		{"0033612345678", "MA", "+33612345678"},
+		{"00 212 600 000 000", "FR", "+212600000000"},
+		{"+1 415 555 2671", "MA", "+14155552671"},
+		// Calling code of a country without rules: only the overall length is checked
+		{"+7 912 345 67 89", "MA", "+79123456789"},
+		{"0079123456789", "MA", "+79123456789"},
+		{" +212 600000000 ", "MA", "+212600000000"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.raw, tt.country)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, %v, want %q", tt.raw, tt.country, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	tests := []struct {
+		raw     string
+		country string
+		want    error
+	}{
+		{"", "MA", ErrEmpty},
+		{"   ", "MA", ErrEmpty},
+		{"( ) -", "MA", ErrEmpty},
+		{"+", "MA", ErrEmpty},
+		{"00", "MA", ErrLength},
+		{"0612", "MA", ErrLength},
+		{"06 00 00 00 00 0", "MA", ErrLength},
+		{"+212 6000", "FR", ErrLength},
+		{"+33 6 12 34 56 78 9", "MA", ErrLength},
+		{"+06 00 00 00 00", "MA", ErrLength}, // No calling code starts with 0
+		{"+999 1234", "MA", ErrLength},
+		{"+1234567890123456", "MA", ErrLength}, // Longer than E.164
+		{"06 00 00 00 0x", "MA", ErrCharacters},
+		{"+212#600000000", "MA", ErrCharacters},
+		{"06 00 00 00 00 poste 12", "MA", ErrCharacters},
+		{"212+600000000", "MA", ErrCharacters},
+		{"0600000000", "XX", ErrCountry},
+		{"0600000000", "", ErrCountry},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.raw, tt.country)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Normalize(%q, %q) = %q, %v, want %v", tt.raw, tt.country, got, err, tt.want)
+		}
+	}
+}
+
+// Normalize must accept its own output, so numbers can be saved again unchanged
+func TestNormalizeIdempotent(t *testing.T) {
+	for _, raw := range []string{"+212600000000", "+33612345678", "+14155552671", "+79123456789"} {
+		for _, country := range []string{"MA", "FR", "US"} {
+			got, err := Normalize(raw, country)
+			if err != nil || got != raw {
+				t.Errorf("Normalize(%q, %q) = %q, %v, want it unchanged", raw, country, got, err)
+			}
+		}
+	}
+}
+
+func TestLookupCountry(t *testing.T) {
+	for _, code := range []string{"MA", "ma", " fr "} {
+		if _, ok := LookupCountry(code); !ok {
+			t.Errorf("LookupCountry(%q) not found", code)
+		}
+	}
+	for _, code := range []string{"", "XX", "MAR"} {
+		if _, ok := LookupCountry(code); ok {
+			t.Errorf("LookupCountry(%q) found, want unsupported", code)
+		}
+	}
+	if _, ok := LookupCountry(DefaultCountry); !ok {
+		t.Errorf("DefaultCountry %q is not supported", DefaultCountry)
+	}
+}
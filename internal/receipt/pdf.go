@@ -0,0 +1,128 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDF page layout (points); A4 with the receipt typeset in Courier so columns line up
+const (
+	pdfPageWidth   = 595
+	pdfPageHeight  = 842
+	pdfMargin      = 56
+	pdfFontSize    = 10
+	pdfLineHeight  = 13
+	pdfTitleSize   = 16
+	pdfColumns     = 72 // Courier 10pt is 6pt wide: 72 columns fit between the margins
+	pdfLinesOnPage = (pdfPageHeight - 2*pdfMargin - 2*pdfLineHeight) / pdfLineHeight
+)
+
+// PDF renders the receipt as an A4 invoice
+// The document only uses the PDF standard fonts, so no font file is embedded
+func (r Receipt) PDF() []byte {
+	lines := r.lines(pdfColumns)
+
+	// Split the lines over as many pages as needed
+	var pages [][]string
+	for len(lines) > 0 {
+		n := min(pdfLinesOnPage, len(lines))
+		pages = append(pages, lines[:n])
+		lines = lines[n:]
+	}
+
+	w := &pdfWriter{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fixed objects: 1 catalog, 2 page tree, 3 body font, 4 title font; then page/content pairs
+	pageIDs := make([]int, len(pages))
+	for i := range pages {
+		pageIDs[i] = 5 + 2*i
+	}
+
+	w.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(pageIDs))
+	for i, id := range pageIDs {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	w.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	w.object(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	w.object(4, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, pageLines := range pages {
+		var content bytes.Buffer
+		y := pdfPageHeight - pdfMargin
+
+		// Title on every page
+		title := "FACTURE " + FormatInvoiceNumber(r.InvoiceNumber)
+		if len(pages) > 1 {
+			title += fmt.Sprintf(" (%d/%d)", i+1, len(pages))
+		}
+		fmt.Fprintf(&content, "BT /F2 %d Tf %d %d Td (%s) Tj ET\n", pdfTitleSize, pdfMargin, y, pdfEscape(title))
+		y -= 2 * pdfLineHeight
+
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, y)
+		for _, l := range pageLines {
+			fmt.Fprintf(&content, "(%s) '\n", pdfEscape(l))
+		}
+		content.WriteString("ET\n")
+
+		pageID := pageIDs[i]
+		w.object(pageID, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pageID+1,
+		))
+		w.object(pageID+1, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	return w.finish(1)
+}
+
+// pdfWriter tracks object offsets to build the cross-reference table
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *pdfWriter) object(id int, body string) {
+	if w.offsets == nil {
+		w.offsets = map[int]int{}
+	}
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+func (w *pdfWriter) finish(rootID int) []byte {
+	size := len(w.offsets) + 1
+	xref := w.buf.Len()
+
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for id := 1; id < size; id++ {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", w.offsets[id])
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, rootID, xref)
+	return w.buf.Bytes()
+}
+
+// pdfEscape encodes a string for a PDF literal in WinAnsiEncoding
+// Characters outside the encoding (emoji, Arabic...) are replaced by '?'
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '€':
+			b.WriteString(`\200`)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,180 @@
+// Package receipt renders sales as customer receipts: plain text for 58/80mm
+// thermal printers and a one-document PDF invoice.
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"electronic-shop/internal/money"
+)
+
+// Thermal printer paper widths, in characters per line (Font A)
+const (
+	Width58mm = 32
+	Width80mm = 48
+)
+
+// Line - one item of the receipt
+type Line struct {
+	Name      string
+	Quantity  int
+	UnitPrice money.Amount
+	Total     money.Amount
+}
+
+// Payment - one tender shown at the bottom of the receipt
+type Payment struct {
+	Method string
+	Amount money.Amount
+}
+
+// Receipt - everything printed for a sale; built by the handlers from the stored transaction
+type Receipt struct {
+	ShopName       string
+	WhatsAppNumber string
+	Currency       string
+	InvoiceNumber  int64
+	Date           time.Time
+	Lines          []Line
+	Subtotal       money.Amount
+	Discount       money.Amount
+	DiscountReason string
+	NetAmount      money.Amount // Excluding tax
+	TaxRate        float64
+	TaxAmount      money.Amount
+	Total          money.Amount // Including tax
+	Payments       []Payment
+}
+
+// FormatInvoiceNumber renders a per-shop sequential number, e.g. "FAC-000042"
+func FormatInvoiceNumber(n int64) string {
+	return fmt.Sprintf("FAC-%06d", n)
+}
+
+// Text renders the receipt as a fixed-width layout for a thermal printer
+// width is the number of characters per line (Width58mm or Width80mm)
+func (r Receipt) Text(width int) string {
+	var b strings.Builder
+	for _, l := range r.lines(width) {
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// lines lays the receipt out in lines of at most width characters
+func (r Receipt) lines(width int) []string {
+	format := func(a money.Amount) string { return money.Format(a, r.Currency) }
+	rule := strings.Repeat("-", width)
+
+	var out []string
+	out = append(out, center(r.ShopName, width))
+	if r.WhatsAppNumber != "" {
+		out = append(out, center("WhatsApp : +"+strings.TrimPrefix(r.WhatsAppNumber, "+"), width))
+	}
+	out = append(out, rule)
+	out = append(out, pair("Facture", FormatInvoiceNumber(r.InvoiceNumber), width))
+	out = append(out, pair("Date", r.Date.Format("02/01/2006 15:04"), width))
+	out = append(out, rule)
+
+	for _, l := range r.Lines {
+		out = append(out, wrap(l.Name, width)...)
+		out = append(out, pair(fmt.Sprintf("  %d x %s", l.Quantity, format(l.UnitPrice)), format(l.Total), width))
+	}
+	out = append(out, rule)
+
+	if r.Discount > 0 {
+		out = append(out, pair("Sous-total", format(r.Subtotal), width))
+		out = append(out, pair("Remise", "-"+format(r.Discount), width))
+		if r.DiscountReason != "" {
+			out = append(out, wrap("("+r.DiscountReason+")", width)...)
+		}
+	}
+	if r.TaxAmount > 0 {
+		out = append(out, pair("Total HT", format(r.NetAmount), width))
+		out = append(out, pair(fmt.Sprintf("TVA %s%%", formatRate(r.TaxRate)), format(r.TaxAmount), width))
+	}
+	out = append(out, pair("TOTAL TTC", format(r.Total), width))
+
+	if len(r.Payments) > 0 {
+		out = append(out, rule)
+		for _, p := range r.Payments {
+			out = append(out, pair(paymentLabel(p.Method), format(p.Amount), width))
+		}
+	}
+
+	out = append(out, rule)
+	out = append(out, center("Merci de votre visite !", width))
+
+	// pair may have broken a line in two
+	return strings.Split(strings.Join(out, "\n"), "\n")
+}
+
+// paymentLabel translates payment methods for the customer
+func paymentLabel(method string) string {
+	switch method {
+	case "Cash":
+		return "Espèces"
+	case "Card":
+		return "Carte bancaire"
+	case "BankTransfer":
+		return "Virement"
+	case "MobileMoney":
+		return "Mobile money"
+	}
+	return method
+}
+
+// formatRate prints 20 as "20" and 5.5 as "5.5"
+func formatRate(rate float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".")
+}
+
+// pair puts label on the left and value on the right of a line
+func pair(label, value string, width int) string {
+	space := width - utf8.RuneCountInString(label) - utf8.RuneCountInString(value)
+	if space < 1 {
+		// Not enough room: value on its own line, right-aligned
+		return truncate(label, width) + "\n" + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(value))) + value
+	}
+	return label + strings.Repeat(" ", space) + value
+}
+
+// center pads s so it is centered on the line
+func center(s string, width int) string {
+	s = truncate(s, width)
+	pad := (width - utf8.RuneCountInString(s)) / 2
+	return strings.Repeat(" ", pad) + s
+}
+
+// wrap splits s on spaces into lines of at most width characters
+func wrap(s string, width int) []string {
+	var out []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		word = truncate(word, width)
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			out = append(out, line)
+			line = word
+		}
+	}
+	if line != "" {
+		out = append(out, line)
+	}
+	return out
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
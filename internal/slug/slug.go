@@ -0,0 +1,114 @@
+// Package slug makes and validates the human-friendly identifiers used in public URLs
+// ("Électro Ménager Casa" → "electro-menager-casa").
+package slug
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Length bounds of a slug
+const (
+	MinLength = 3
+	MaxLength = 60
+)
+
+var (
+	ErrLength   = errors.New("slug must be 3 to 60 characters long")
+	ErrFormat   = errors.New("slug may only contain lowercase letters, digits and single dashes between them")
+	ErrUUID     = errors.New("slug must not be a UUID")
+	ErrReserved = errors.New("slug is a reserved word")
+)
+
+var pattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reserved are path segments of the application and words that would mislead customers
+var reserved = map[string]bool{
+	"admin": true, "api": true, "app": true, "assets": true, "auth": true, "cdn": true,
+	"dashboard": true, "favicon": true, "health": true, "help": true, "login": true,
+	"logout": true, "new": true, "public": true, "register": true, "robots": true,
+	"shop": true, "shops": true, "signup": true, "sitemap": true, "static": true,
+	"status": true, "stream": true, "support": true, "uploads": true, "webhooks": true,
+	"www": true,
+}
+
+// fallbackPrefix is put before names that give no valid slug on their own ("A", "Admin")
+const fallbackPrefix = "boutique"
+
+// letters that do not decompose into a base letter and an accent
+var ligatures = strings.NewReplacer("œ", "oe", "æ", "ae", "ß", "ss", "ø", "o", "đ", "d", "ł", "l")
+
+// Make turns a name into a slug candidate: lowercase ASCII words joined by dashes
+// The result is not validated: it can be too short or reserved, see Unique
+func Make(name string) string {
+	name = ligatures.Replace(strings.ToLower(name))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Accent of the previous letter
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+	return truncate(b.String(), MaxLength)
+}
+
+// truncate cuts a slug to n characters, at a dash when there is one
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	if i := strings.LastIndexByte(s, '-'); i >= MinLength {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, "-")
+}
+
+// Validate checks a slug chosen by a user
+func Validate(s string) error {
+	switch {
+	case len(s) < MinLength || len(s) > MaxLength:
+		return ErrLength
+	case !pattern.MatchString(s):
+		return ErrFormat
+	case reserved[s]:
+		return ErrReserved
+	}
+	// Public routes accept a shop UUID or a slug: a slug must never read as a UUID
+	if _, err := uuid.Parse(s); err == nil {
+		return ErrUUID
+	}
+	return nil
+}
+
+// Unique makes a valid slug from a name that taken does not report: "casa", then "casa-2", "casa-3"...
+func Unique(name string, taken func(string) bool) string {
+	base := Make(name)
+	if Validate(base) != nil {
+		base = truncate(strings.Trim(fallbackPrefix+"-"+base, "-"), MaxLength)
+	}
+	// Room for the counter
+	short := truncate(base, MaxLength-4)
+
+	candidate := base
+	for n := 2; Validate(candidate) != nil || taken(candidate); n++ {
+		candidate = short + "-" + strconv.Itoa(n)
+	}
+	return candidate
+}
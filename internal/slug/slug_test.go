@@ -0,0 +1,106 @@
+package slug
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Électro Ménager Casa", "electro-menager-casa"},
+		{"  Ça   coûte -- 100 DH ! ", "ca-coute-100-dh"},
+		{"Œuvres & Cœur", "oeuvres-coeur"},
+		{"Straße Køge", "strasse-koge"},
+		{"Téléphonie à Fès", "telephonie-a-fes"},
+		{"iPhone_15/Pro", "iphone-15-pro"},
+		{"متجر الهواتف Casa", "casa"}, // Letters without ASCII form are dropped
+		{"!!!", ""},
+		{"", ""},
+		{strings.Repeat("abcdefghij ", 10), "abcdefghij-abcdefghij-abcdefghij-abcdefghij-abcdefghij"},
+		{strings.Repeat("a", 80), strings.Repeat("a", MaxLength)},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.name); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		slug string
+		want error
+	}{
+		{"electro-casa", nil},
+		{"abc", nil},
+		{"shop-2", nil},
+		{strings.Repeat("a", MaxLength), nil},
+		{"ab", ErrLength},
+		{strings.Repeat("a", MaxLength+1), ErrLength},
+		{"Electro-Casa", ErrFormat},
+		{"electro--casa", ErrFormat},
+		{"-electro", ErrFormat},
+		{"electro-", ErrFormat},
+		{"électro", ErrFormat},
+		{"electro casa", ErrFormat},
+		{"admin", ErrReserved},
+		{"api", ErrReserved},
+		{"public", ErrReserved},
+		{"0b5f9b4e-8c1f-4e8e-9d7a-3c2b1a0f9e8d", ErrUUID},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.slug); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%q) = %v, want %v", tt.slug, err, tt.want)
+		}
+	}
+}
+
+func TestUnique(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{"Électro Casa", nil, "electro-casa"},
+		{"Électro Casa", []string{"electro-casa"}, "electro-casa-2"},
+		{"Électro Casa", []string{"electro-casa", "electro-casa-2", "electro-casa-3"}, "electro-casa-4"},
+		// Reserved words and too short names get the prefix
+		{"Admin", nil, "boutique-admin"},
+		{"API", nil, "boutique-api"},
+		{"Admin", []string{"boutique-admin"}, "boutique-admin-2"},
+		{"A", nil, "boutique-a"},
+		// Nothing usable in the name
+		{"", nil, "boutique"},
+		{"!!!", nil, "boutique"},
+		{"متجر", []string{"boutique"}, "boutique-2"},
+		// A name reading as a UUID
+		{"0B5F9B4E 8C1F 4E8E 9D7A 3C2B1A0F9E8D", nil, "boutique-0b5f9b4e-8c1f-4e8e-9d7a-3c2b1a0f9e8d"},
+	}
+	for _, tt := range tests {
+		got := Unique(tt.name, func(s string) bool { return slices.Contains(tt.taken, s) })
+		if got != tt.want {
+			t.Errorf("Unique(%q, %v) = %q, want %q", tt.name, tt.taken, got, tt.want)
+		}
+		if err := Validate(got); err != nil {
+			t.Errorf("Unique(%q) = %q is not valid: %v", tt.name, got, err)
+		}
+	}
+}
+
+// A long name keeps its suffix within MaxLength
+func TestUniqueLongName(t *testing.T) {
+	name := strings.Repeat("a", MaxLength)
+	got := Unique(name, func(s string) bool { return s == name })
+
+	if want := strings.Repeat("a", MaxLength-4) + "-2"; got != want {
+		t.Errorf("Unique = %q, want %q", got, want)
+	}
+	if err := Validate(got); err != nil {
+		t.Errorf("Unique = %q is not valid: %v", got, err)
+	}
+}
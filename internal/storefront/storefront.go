@@ -0,0 +1,118 @@
+// Package storefront renders the public HTML catalog of a shop (/s/<slug>) with html/template.
+//
+// Pages only receive the view types below, built by the handlers from the public product
+// responses: purchase prices never reach a template.
+package storefront
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+)
+
+// Page names
+const (
+	PageList     = "list"
+	PageProduct  = "product"
+	PageNotFound = "not_found"
+)
+
+//go:embed templates/*.html
+var files embed.FS
+
+// pages - each page is parsed with the layout into its own set, as they all define "content"
+var pages = map[string]*template.Template{}
+
+func init() {
+	for _, name := range []string{PageList, PageProduct, PageNotFound} {
+		pages[name] = template.Must(template.ParseFS(files, "templates/layout.html", "templates/"+name+".html"))
+	}
+}
+
+// Meta - title and OpenGraph link preview of a page
+type Meta struct {
+	Title         string
+	Description   string
+	URL           string // Absolute canonical URL
+	Image         string // Absolute URL, optional
+	Type          string // OpenGraph type: "website" or "product"
+	PriceAmount   string // Product pages: price for link previews, e.g. "1299.99"
+	PriceCurrency string
+}
+
+type Shop struct {
+	Name string
+	URL  string // Home page of the storefront
+}
+
+type Category struct {
+	Name    string
+	URL     string
+	Count   int
+	Current bool
+}
+
+type Image struct {
+	URL string
+	Alt string
+}
+
+// Product - what customers see of a product, prices already formatted in the shop currency
+type Product struct {
+	Name             string
+	Description      string
+	Category         string
+	CategoryURL      string
+	URL              string
+	Price            string
+	PromotionalPrice string // Set when an automatic promotion applies
+	Promotion        string
+	TaxNote          string // "TTC" or "HT"
+	StockStatus      string
+	Available        bool
+	ImageURL         string
+	Images           []Image
+	WhatsAppLink     string
+}
+
+type SortOption struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// Page - what every page shows around its content
+type Page struct {
+	Meta       Meta
+	Shop       Shop
+	Categories []Category
+	Query      string // Content of the search box
+}
+
+// ListPage - home, category and search results
+type ListPage struct {
+	Page
+	Heading    string
+	Products   []Product
+	Total      int64
+	Sorts      []SortOption
+	PageNumber int
+	TotalPages int
+	PrevURL    string
+	NextURL    string
+}
+
+type ProductPage struct {
+	Page
+	Product Product
+	Related []Product
+}
+
+// Render executes a page with its data (ListPage, ProductPage, or Page for PageNotFound)
+func Render(page string, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pages[page].ExecuteTemplate(&buf, "layout", data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
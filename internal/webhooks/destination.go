@@ -0,0 +1,95 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Outbound requests to URLs chosen by shops (webhook endpoints, alert webhooks) must only reach
+// the public internet: anyone can register a shop, and must not make the server call its own
+// network (databases, admin ports, cloud metadata at 169.254.169.254)
+
+var (
+	ErrDestinationURL     = errors.New("URL must be an absolute http(s) URL")
+	ErrPrivateDestination = errors.New("URL must point to a public address, not a private, loopback or link-local one")
+	ErrUnresolvable       = errors.New("URL host cannot be resolved")
+)
+
+// cgnat - shared address space (RFC 6598), private to the carrier network
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicIP reports whether an address is on the public internet
+func publicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4 // IPv4-mapped IPv6 ("::ffff:127.0.0.1") is checked as IPv4
+		if ip[0] == 0 || cgnat.Contains(ip) || ip.Equal(net.IPv4bcast) {
+			return false
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// CheckURL validates a destination when it is saved: every address its host resolves to must be public
+// Connections are checked again by NewClient, as DNS answers can change after this check
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrDestinationURL
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return ErrPrivateDestination
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return ErrUnresolvable
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return ErrPrivateDestination
+		}
+	}
+	return nil
+}
+
+// dialControl refuses connections to non-public addresses, once DNS is resolved
+// It applies to every connection, redirects included
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return ErrPrivateDestination
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client that can only connect to public addresses
+// It ignores proxy settings: a proxy would make the connection for it, unchecked
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // Cloud metadata
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"255.255.255.255", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"::", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := publicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("publicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://8.8.8.8/hook", nil},
+		{"http://[2606:4700::1111]:8080/hook", nil},
+		{"http://127.0.0.1:8080/hook", ErrPrivateDestination},
+		{"http://169.254.169.254/latest/meta-data/", ErrPrivateDestination},
+		{"http://[::1]/hook", ErrPrivateDestination},
+		{"http://localhost:5432/", ErrPrivateDestination},
+		{"ftp://8.8.8.8/hook", ErrDestinationURL},
+		{"/hook", ErrDestinationURL},
+		{"http:///hook", ErrDestinationURL},
+	}
+	for _, tt := range tests {
+		if err := CheckURL(context.Background(), tt.url); !errors.Is(err, tt.want) {
+			t.Errorf("CheckURL(%q) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+// The client refuses the connection itself, whatever the URL was checked against
+func TestNewClientRefusesPrivateAddresses(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer srv.Close()
+
+	resp, err := NewClient(time.Second).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrPrivateDestination) {
+		t.Errorf("Get(%s) = %v, want %v", srv.URL, err, ErrPrivateDestination)
+	}
+	if called {
+		t.Error("the request reached the server")
+	}
+}
@@ -0,0 +1,158 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"electronic-shop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	batchSize    = 20
+	lease        = 5 * time.Minute // A claimed delivery is not picked again before, even if its worker died
+	firstBackoff = 30 * time.Second
+	maxBackoff   = 6 * time.Hour
+)
+
+// Sender delivers due outbox rows; run DeliverDue periodically
+type Sender struct {
+	db          *gorm.DB
+	client      *http.Client
+	maxAttempts int
+}
+
+// NewSender - retries are bounded by WEBHOOK_MAX_ATTEMPTS (default 10, about 4h of retries)
+func NewSender(db *gorm.DB) *Sender {
+	maxAttempts := 10
+	if v, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && v > 0 {
+		maxAttempts = v
+	}
+	client := NewClient(10 * time.Second)
+	// A redirect is an answer like any other: the endpoint URL must be the final one
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return &Sender{db: db, client: client, maxAttempts: maxAttempts}
+}
+
+// Backoff returns the delay before the retry that follows the given number of failed attempts
+func Backoff(attempts int) time.Duration {
+	d := firstBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+// DeliverDue sends the pending deliveries whose time has come
+// Rows are claimed with SKIP LOCKED, so several instances can run it side by side
+func (s *Sender) DeliverDue(ctx context.Context) error {
+	for {
+		var deliveries []models.WebhookDelivery
+		now := time.Now()
+		if err := s.db.WithContext(ctx).Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = ? AND next_attempt_at <= ?
+				ORDER BY next_attempt_at LIMIT ?
+				FOR UPDATE SKIP LOCKED)
+			RETURNING *`, now.Add(lease), models.WebhookPending, now, batchSize).
+			Scan(&deliveries).Error; err != nil {
+			return err
+		}
+
+		for _, d := range deliveries {
+			if err := s.deliver(ctx, d); err != nil {
+				return err
+			}
+		}
+		if len(deliveries) < batchSize {
+			return nil
+		}
+	}
+}
+
+// deliver makes one attempt and records its outcome; only DB errors are returned
+func (s *Sender) deliver(ctx context.Context, d models.WebhookDelivery) error {
+	db := s.db.WithContext(ctx)
+
+	var endpoint models.WebhookEndpoint
+	err := db.First(&endpoint, "id = ? AND shop_id = ?", d.EndpointID, d.ShopID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err != nil || !endpoint.Active {
+		return db.Model(&d).Updates(map[string]interface{}{
+			"status":     models.WebhookFailed,
+			"last_error": "endpoint disabled",
+		}).Error
+	}
+
+	attempt := models.WebhookAttempt{DeliveryID: d.ID, Attempt: d.Attempts + 1}
+	started := time.Now()
+	attempt.StatusCode, err = s.post(ctx, endpoint, d)
+	attempt.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	updates := map[string]interface{}{
+		"attempts":         attempt.Attempt,
+		"last_status_code": attempt.StatusCode,
+		"last_error":       attempt.Error,
+	}
+	switch {
+	case err == nil:
+		updates["status"] = models.WebhookDelivered
+		updates["delivered_at"] = time.Now()
+	case attempt.Attempt >= s.maxAttempts:
+		updates["status"] = models.WebhookFailed
+	default:
+		updates["next_attempt_at"] = time.Now().Add(Backoff(attempt.Attempt))
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&d).Updates(updates).Error
+	})
+}
+
+// post sends the signed payload; any answer other than 2xx is an error
+// Only the status code is kept: the endpoint's answer is never stored nor shown to the shop
+func (s *Sender) post(ctx context.Context, endpoint models.WebhookEndpoint, d models.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "electronic-shop-webhooks")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,142 @@
+// Package webhooks pushes shop events to the shops' own HTTP endpoints.
+//
+// Events are written to an outbox table in the same DB transaction as the change,
+// then delivered, signed and retried with backoff, by a background worker.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"electronic-shop/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Events a shop can subscribe to
+const (
+	EventProductCreated     = "product.created"
+	EventProductUpdated     = "product.updated"
+	EventProductDeleted     = "product.deleted"
+	EventTransactionCreated = "transaction.created"
+	EventStockLow           = "stock.low"
+	EventUserCreated        = "user.created"
+)
+
+// AllEvents lists every event, in documentation order
+var AllEvents = []string{
+	EventProductCreated, EventProductUpdated, EventProductDeleted,
+	EventTransactionCreated, EventStockLow, EventUserCreated,
+}
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Envelope - body of every delivery
+type Envelope struct {
+	ID        uuid.UUID       `json:"id"`
+	Event     string          `json:"event"`
+	ShopID    uuid.UUID       `json:"shop_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ValidEvent reports whether name is a known event
+func ValidEvent(name string) bool {
+	for _, e := range AllEvents {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribes reports whether the endpoint wants the event
+func Subscribes(endpoint models.WebhookEndpoint, event string) bool {
+	for _, e := range strings.Split(endpoint.Events, ",") {
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSecret returns a random signing secret
+func NewSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// Sign returns the signature header value of a payload sent at the given unix time:
+// "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>" with the endpoint secret
+// Signing the timestamp lets receivers reject replayed deliveries
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue writes the event to the outbox of every active endpoint of the shop subscribed to it
+// Pass the DB transaction of the change, so the event is recorded if and only if the change is
+func Enqueue(tx *gorm.DB, shopID uuid.UUID, event string, data interface{}) error {
+	var endpoints []models.WebhookEndpoint
+	if err := tx.Where("shop_id = ? AND active = ?", shopID, true).Find(&endpoints).Error; err != nil {
+		return err
+	}
+
+	var subscribed []models.WebhookEndpoint
+	for _, e := range endpoints {
+		if Subscribes(e, event) {
+			subscribed = append(subscribed, e)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	eventID := uuid.New()
+	payload, err := json.Marshal(Envelope{
+		ID:        eventID,
+		Event:     event,
+		ShopID:    shopID,
+		CreatedAt: now,
+		Data:      raw,
+	})
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(subscribed))
+	for _, e := range subscribed {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    e.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.WebhookPending,
+			NextAttemptAt: now,
+			ShopID:        shopID,
+		})
+	}
+	return tx.Create(&deliveries).Error
+}
@@ -0,0 +1,108 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"electronic-shop/internal/models"
+)
+
+func TestSign(t *testing.T) {
+	// Expected values computed independently:
+	// printf '%s' '<timestamp>.<body>' | openssl dgst -sha256 -hmac '<secret>'
+	tests := []struct {
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{
+			secret:    "whsec_test",
+			timestamp: 1700000000,
+			body:      `{"event":"product.created","data":{"name":"Écran"}}`,
+			want:      "sha256=1961664e4f8030c020bd51684d397e46071f6a884180e403d89ff4c40007ae72",
+		},
+		{
+			secret:    "whsec_test",
+			timestamp: 0,
+			body:      "",
+			want:      "sha256=a2fa7a43c6a1cf2e784eaf3327d65c65b3d2b790320ebed9aa5661bc42a8cccd",
+		},
+	}
+	for _, tt := range tests {
+		if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("Sign(%q, %d, %q) = %s, want %s", tt.secret, tt.timestamp, tt.body, got, tt.want)
+		}
+	}
+
+	// The timestamp is signed: replaying the body with another one does not verify
+	body := []byte(`{"event":"product.created"}`)
+	if Sign("whsec_test", 1700000000, body) == Sign("whsec_test", 1700000001, body) {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if Sign("whsec_test", 1700000000, body) == Sign("whsec_other", 1700000000, body) {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{9, 128 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, maxBackoff}, // 512 minutes, capped
+		{12, maxBackoff},
+		{1000, maxBackoff}, // No overflow
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+
+	// NewSender documents about 4h of retries for the default 10 attempts
+	var total time.Duration
+	for attempts := 1; attempts < 10; attempts++ {
+		total += Backoff(attempts)
+	}
+	if total < 4*time.Hour || total > 5*time.Hour {
+		t.Errorf("retries of 10 attempts span %v, want about 4h", total)
+	}
+}
+
+func TestSubscribes(t *testing.T) {
+	tests := []struct {
+		events string
+		event  string
+		want   bool
+	}{
+		{"*", EventTransactionCreated, true},
+		{EventTransactionCreated, EventTransactionCreated, true},
+		{EventProductCreated + "," + EventTransactionCreated, EventTransactionCreated, true},
+		{EventProductCreated, EventTransactionCreated, false},
+		{"", EventTransactionCreated, false},
+	}
+	for _, tt := range tests {
+		if got := Subscribes(models.WebhookEndpoint{Events: tt.events}, tt.event); got != tt.want {
+			t.Errorf("Subscribes(%q, %q) = %v, want %v", tt.events, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, b := NewSecret(), NewSecret()
+	if !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+64 {
+		t.Errorf("NewSecret() = %q, want whsec_ and 64 hex digits", a)
+	}
+	if a == b {
+		t.Error("NewSecret() returned the same secret twice")
+	}
+}
@@ -0,0 +1,153 @@
+// Package whatsapp builds the click-to-chat links of the public catalog and the
+// customizable messages they pre-fill.
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage - language of shops that did not choose one
+const DefaultLanguage = "fr"
+
+// DefaultTemplate - message of shops without a template for the customer's language
+const DefaultTemplate = "Bonjour je veux plus d'information sur {product_name}"
+
+// MaxTemplateLength - WhatsApp pre-filled texts stay readable well under this
+const MaxTemplateLength = 1000
+
+// Placeholders a template may use
+const (
+	PlaceholderProductName = "product_name"
+	PlaceholderPrice       = "price"
+	PlaceholderProductURL  = "product_url"
+	PlaceholderSKU         = "sku"
+	PlaceholderShopName    = "shop_name"
+)
+
+var Placeholders = []string{PlaceholderProductName, PlaceholderPrice, PlaceholderProductURL, PlaceholderSKU, PlaceholderShopName}
+
+var (
+	ErrEmptyTemplate   = errors.New("template must not be empty")
+	ErrTemplateLength  = fmt.Errorf("template must be at most %d characters", MaxTemplateLength)
+	ErrNoProduct       = errors.New("template must name the product with {product_name}, {sku} or {product_url}")
+	ErrInvalidLanguage = errors.New("invalid language code, e.g. fr, ar, en or fr-MA")
+)
+
+// Vars - values of the placeholders for one product
+type Vars struct {
+	ProductName string
+	Price       string // Formatted in the shop currency
+	ProductURL  string
+	SKU         string
+	ShopName    string
+}
+
+// placeholderNames returns the placeholders of a template in order, or the first syntax error
+func placeholderNames(body string) ([]string, error) {
+	var names []string
+	rest := body
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			return names, nil
+		}
+		if rest[open] == '}' {
+			return nil, errors.New(`"}" without "{"`)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] == '{' {
+			return nil, errors.New(`"{" is not closed`)
+		}
+		names = append(names, rest[open+1:open+1+end])
+		rest = rest[open+end+2:]
+	}
+}
+
+// ValidateTemplate checks a message template before it is saved
+func ValidateTemplate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return ErrEmptyTemplate
+	}
+	if utf8.RuneCountInString(body) > MaxTemplateLength {
+		return ErrTemplateLength
+	}
+
+	names, err := placeholderNames(body)
+	if err != nil {
+		return err
+	}
+	namesProduct := false
+	for _, name := range names {
+		switch name {
+		case PlaceholderProductName, PlaceholderSKU, PlaceholderProductURL:
+			namesProduct = true
+		case PlaceholderPrice, PlaceholderShopName:
+		default:
+			return fmt.Errorf("unknown placeholder {%s}, use %s", name, "{"+strings.Join(Placeholders, "}, {")+"}")
+		}
+	}
+	if !namesProduct {
+		return ErrNoProduct
+	}
+	return nil
+}
+
+// Render fills a validated template
+func Render(body string, v Vars) string {
+	return strings.NewReplacer(
+		"{"+PlaceholderProductName+"}", v.ProductName,
+		"{"+PlaceholderPrice+"}", v.Price,
+		"{"+PlaceholderProductURL+"}", v.ProductURL,
+		"{"+PlaceholderSKU+"}", v.SKU,
+		"{"+PlaceholderShopName+"}", v.ShopName,
+	).Replace(body)
+}
+
+// Link returns the click-to-chat URL opening a conversation with the number, message pre-filled
+// number is in E.164; wa.me takes its digits without "+"
+func Link(number, message string) string {
+	return fmt.Sprintf("https://wa.me/%s?text=%s", strings.TrimPrefix(number, "+"), url.QueryEscape(message))
+}
+
+// ParseLanguage canonicalizes a language code ("FR" → "fr", "fr_ma" → "fr-MA")
+func ParseLanguage(code string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(code))
+	if err != nil || tag == language.Und {
+		return "", ErrInvalidLanguage
+	}
+	return tag.String(), nil
+}
+
+// MatchLanguage picks, among the languages a shop has templates for, the best one for the customer
+// lang (an explicit ?lang) wins over acceptLanguage (the Accept-Language header); "" when none fits
+func MatchLanguage(available []string, lang, acceptLanguage string) string {
+	if len(available) == 0 {
+		return ""
+	}
+	tags := make([]language.Tag, 0, len(available))
+	for _, code := range available {
+		tags = append(tags, language.Make(code))
+	}
+
+	var preferred []language.Tag
+	if tag, err := language.Parse(lang); err == nil && lang != "" {
+		preferred = []language.Tag{tag}
+	} else {
+		preferred, _, _ = language.ParseAcceptLanguage(acceptLanguage)
+	}
+	if len(preferred) == 0 {
+		return ""
+	}
+
+	_, index, confidence := language.NewMatcher(tags).Match(preferred...)
+	if confidence == language.No {
+		return ""
+	}
+	return available[index]
+}
@@ -0,0 +1,138 @@
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultTimezone - time zone of the opening hours of shops that did not choose one
+const DefaultTimezone = "Africa/Casablanca"
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+var ErrHours = errors.New(`opens and closes must both be set as "HH:MM", or both left empty for all day`)
+
+// Schedule - when a route applies, in the shop's time zone
+// Days lists days and ranges ("mon-fri,sat"), empty for every day. Opens and Closes are "HH:MM",
+// both empty for all day; when Closes is not after Opens the range ends the next day ("20:00"-"02:00").
+type Schedule struct {
+	Days   string
+	Opens  string
+	Closes string
+}
+
+// parseDays returns the weekdays of a Days list; none for every day
+func parseDays(days string) ([]time.Weekday, error) {
+	var result []time.Weekday
+	for _, part := range strings.Split(strings.ToLower(strings.ReplaceAll(days, " ", "")), ",") {
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+		from, to := slices.Index(weekdays, first), slices.Index(weekdays, last)
+		if from < 0 || to < 0 {
+			return nil, fmt.Errorf("invalid day %q, use %s or a range like mon-fri", part, strings.Join(weekdays, ", "))
+		}
+		// Ranges may wrap around the week ("fri-mon")
+		for d := from; ; d = (d + 1) % 7 {
+			if !slices.Contains(result, time.Weekday(d)) {
+				result = append(result, time.Weekday(d))
+			}
+			if d == to {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// parseClock returns the minutes since midnight of "HH:MM"
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, ErrHours
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks a schedule before it is saved
+func (s Schedule) Validate() error {
+	if _, err := parseDays(s.Days); err != nil {
+		return err
+	}
+	if s.Opens == "" && s.Closes == "" {
+		return nil
+	}
+	if _, err := parseClock(s.Opens); err != nil {
+		return err
+	}
+	_, err := parseClock(s.Closes)
+	return err
+}
+
+// Normalize returns the schedule in its stored form: lower-case days without spaces
+func (s Schedule) Normalize() Schedule {
+	s.Days = strings.ToLower(strings.ReplaceAll(s.Days, " ", ""))
+	return s
+}
+
+// Open reports whether the schedule covers the time, already in the shop's time zone
+// An invalid schedule is never open
+func (s Schedule) Open(at time.Time) bool {
+	days, err := parseDays(s.Days)
+	if err != nil {
+		return false
+	}
+	onDay := func(d time.Weekday) bool { return len(days) == 0 || slices.Contains(days, d) }
+	if s.Opens == "" && s.Closes == "" {
+		return onDay(at.Weekday())
+	}
+
+	opens, err1 := parseClock(s.Opens)
+	closes, err2 := parseClock(s.Closes)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	now := at.Hour()*60 + at.Minute()
+	if opens < closes {
+		return onDay(at.Weekday()) && now >= opens && now < closes
+	}
+	// Overnight: the part after midnight belongs to the previous day
+	if now >= opens {
+		return onDay(at.Weekday())
+	}
+	return now < closes && onDay((at.Weekday()+6)%7)
+}
+
+// Route - inquiries about Category ("" for every product) go to Number while Schedule is open
+type Route struct {
+	Label    string
+	Number   string
+	Category string
+	Schedule Schedule
+}
+
+// Pick returns the first open route for the category, trying the category's own routes before
+// those for every product; false when none is open and the shop's fallback number is used
+func Pick(routes []Route, category string, at time.Time) (Route, bool) {
+	for _, own := range []bool{true, false} {
+		for _, r := range routes {
+			if own != (r.Category != "") {
+				continue
+			}
+			if own && !strings.EqualFold(r.Category, category) {
+				continue
+			}
+			if r.Schedule.Open(at) {
+				return r, true
+			}
+		}
+	}
+	return Route{}, false
+}
@@ -0,0 +1,218 @@
+package whatsapp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// 2024-06-03 is a Monday
+func day(weekday time.Weekday, clock string) time.Time {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		panic(err)
+	}
+	return time.Date(2024, 6, 3+int(weekday-time.Monday+7)%7, t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestScheduleValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  bool
+		errIs    error
+	}{
+		{name: "every day, all day", schedule: Schedule{}},
+		{name: "week days", schedule: Schedule{Days: "mon-fri", Opens: "09:00", Closes: "18:00"}},
+		{name: "list and range", schedule: Schedule{Days: "Mon, WED-fri ,sun"}},
+		{name: "range across the week end", schedule: Schedule{Days: "fri-mon"}},
+		{name: "overnight", schedule: Schedule{Opens: "20:00", Closes: "02:00"}},
+		{name: "single digit hour", schedule: Schedule{Opens: "9:00", Closes: "18:00"}},
+		{name: "trailing comma", schedule: Schedule{Days: "sat,"}},
+		{name: "unknown day", schedule: Schedule{Days: "monday"}, wantErr: true},
+		{name: "unknown range end", schedule: Schedule{Days: "mon-xyz"}, wantErr: true},
+		{name: "opens alone", schedule: Schedule{Opens: "09:00"}, errIs: ErrHours},
+		{name: "closes alone", schedule: Schedule{Closes: "18:00"}, errIs: ErrHours},
+		{name: "hour out of range", schedule: Schedule{Opens: "09:00", Closes: "24:00"}, errIs: ErrHours},
+		{name: "not a clock", schedule: Schedule{Opens: "9h", Closes: "18h"}, errIs: ErrHours},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			switch {
+			case tt.errIs != nil:
+				if !errors.Is(err, tt.errIs) {
+					t.Errorf("Validate() = %v, want %v", err, tt.errIs)
+				}
+			case tt.wantErr:
+				if err == nil {
+					t.Error("Validate() = nil, want an error")
+				}
+			case err != nil:
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestScheduleNormalize(t *testing.T) {
+	tests := []struct {
+		days string
+		want string
+	}{
+		{"", ""},
+		{"mon-fri", "mon-fri"},
+		{"Mon - Fri, SAT", "mon-fri,sat"},
+		{" sun ", "sun"},
+	}
+	for _, tt := range tests {
+		s := Schedule{Days: tt.days, Opens: "09:00", Closes: "18:00"}.Normalize()
+		if s.Days != tt.want || s.Opens != "09:00" || s.Closes != "18:00" {
+			t.Errorf("Normalize(%q) = %+v, want days %q and hours unchanged", tt.days, s, tt.want)
+		}
+		if err := s.Validate(); err != nil {
+			t.Errorf("Normalize(%q) is not valid: %v", tt.days, err)
+		}
+	}
+}
+
+func TestScheduleOpen(t *testing.T) {
+	weekDays := Schedule{Days: "mon-fri", Opens: "09:00", Closes: "18:00"}
+	fridayNight := Schedule{Days: "fri", Opens: "20:00", Closes: "02:00"}
+	weekEndNights := Schedule{Days: "fri-sun", Opens: "22:00", Closes: "06:00"}
+	saturdayNight := Schedule{Days: "sat", Opens: "22:00", Closes: "06:00"}
+	sundays := Schedule{Days: "sun"}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		at       time.Time
+		want     bool
+	}{
+		{"opening minute", weekDays, day(time.Monday, "09:00"), true},
+		{"before opening", weekDays, day(time.Monday, "08:59"), false},
+		{"closing minute", weekDays, day(time.Friday, "18:00"), false},
+		{"last minute", weekDays, day(time.Friday, "17:59"), true},
+		{"week end", weekDays, day(time.Saturday, "10:00"), false},
+
+		{"overnight, evening", fridayNight, day(time.Friday, "23:00"), true},
+		{"overnight, after midnight", fridayNight, day(time.Saturday, "01:59"), true},
+		{"overnight, closing minute", fridayNight, day(time.Saturday, "02:00"), false},
+		{"overnight, early friday belongs to thursday", fridayNight, day(time.Friday, "01:00"), false},
+		{"overnight, next evening", fridayNight, day(time.Saturday, "20:30"), false},
+
+		{"wrapping range, sunday night", weekEndNights, day(time.Sunday, "23:00"), true},
+		{"wrapping range, monday morning from sunday", weekEndNights, day(time.Monday, "05:00"), true},
+		{"wrapping range, monday night", weekEndNights, day(time.Monday, "23:00"), false},
+		{"wrapping range, friday morning from thursday", weekEndNights, day(time.Friday, "05:00"), false},
+		{"saturday night ends on sunday", saturdayNight, day(time.Sunday, "01:00"), true},
+		{"saturday morning from friday", saturdayNight, day(time.Saturday, "01:00"), false},
+
+		{"all day", sundays, day(time.Sunday, "00:00"), true},
+		{"all day, end of day", sundays, day(time.Sunday, "23:59"), true},
+		{"all day, next day", sundays, day(time.Monday, "00:00"), false},
+		{"every day, all day", Schedule{}, day(time.Wednesday, "03:00"), true},
+
+		{"invalid days", Schedule{Days: "someday"}, day(time.Monday, "12:00"), false},
+		{"invalid hours", Schedule{Opens: "noon", Closes: "18:00"}, day(time.Monday, "12:00"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Open(tt.at); got != tt.want {
+				t.Errorf("Open(%s) = %v, want %v", tt.at.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+// The same instant falls in or out of the hours depending on the shop's time zone
+func TestScheduleOpenTimezones(t *testing.T) {
+	weekDays := Schedule{Days: "mon-fri", Opens: "09:00", Closes: "18:00"}
+
+	tests := []struct {
+		zone string
+		at   time.Time
+		want bool
+	}{
+		// Monday 08:30 UTC
+		{"UTC", time.Date(2024, 6, 3, 8, 30, 0, 0, time.UTC), false},
+		{"Africa/Casablanca", time.Date(2024, 6, 3, 8, 30, 0, 0, time.UTC), true}, // 09:30, UTC+1
+		{"Asia/Tokyo", time.Date(2024, 6, 3, 8, 30, 0, 0, time.UTC), true},        // 17:30
+		{"America/New_York", time.Date(2024, 6, 3, 8, 30, 0, 0, time.UTC), false}, // 04:30
+		// Morocco moves back to UTC during Ramadan
+		{"Africa/Casablanca", time.Date(2024, 3, 20, 8, 30, 0, 0, time.UTC), false},
+		// Friday 21:30 UTC is Saturday morning in Tokyo, Friday afternoon in New York
+		{"Asia/Tokyo", time.Date(2024, 6, 7, 21, 30, 0, 0, time.UTC), false},
+		{"America/New_York", time.Date(2024, 6, 7, 21, 30, 0, 0, time.UTC), true},
+		// Sunday 23:30 UTC is Monday morning in Tokyo
+		{"UTC", time.Date(2024, 6, 2, 23, 30, 0, 0, time.UTC), false},
+		{"Asia/Tokyo", time.Date(2024, 6, 2, 23, 30, 0, 0, time.UTC), false}, // 08:30
+		{"Asia/Tokyo", time.Date(2024, 6, 3, 0, 30, 0, 0, time.UTC), true},   // 09:30
+	}
+	for _, tt := range tests {
+		at := tt.at.In(mustLoad(t, tt.zone))
+		if got := weekDays.Open(at); got != tt.want {
+			t.Errorf("Open(%s in %s) = %v, want %v", tt.at.Format(time.RFC3339), tt.zone, got, tt.want)
+		}
+	}
+
+	// Overnight hours in the shop's zone: Friday 23:00 in New York is Saturday 03:00 UTC
+	nights := Schedule{Days: "fri", Opens: "20:00", Closes: "02:00"}
+	at := time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC)
+	if !nights.Open(at.In(mustLoad(t, "America/New_York"))) {
+		t.Error("Friday night route closed at 23:00 in New York")
+	}
+	if nights.Open(at) {
+		t.Error("Friday night route open at 03:00 UTC on Saturday")
+	}
+}
+
+func TestPick(t *testing.T) {
+	routes := []Route{
+		{Label: "phones-day", Category: "Phones", Schedule: Schedule{Days: "mon-fri", Opens: "09:00", Closes: "18:00"}},
+		{Label: "phones-night", Category: "phones", Schedule: Schedule{Days: "mon-fri", Opens: "18:00", Closes: "09:00"}},
+		{Label: "tv-saturday", Category: "TV", Schedule: Schedule{Days: "sat"}},
+		{Label: "shop", Schedule: Schedule{Opens: "10:00", Closes: "20:00"}},
+		{Label: "shop-backup", Schedule: Schedule{Opens: "08:00", Closes: "22:00"}},
+	}
+
+	tests := []struct {
+		name     string
+		category string
+		at       time.Time
+		want     string // "" when no route is open
+	}{
+		{"own category", "Phones", day(time.Monday, "10:00"), "phones-day"},
+		{"category is case-insensitive", "PHONES", day(time.Monday, "10:00"), "phones-day"},
+		{"own category before every product", "Phones", day(time.Monday, "19:00"), "phones-night"},
+		{"own overnight route after midnight", "Phones", day(time.Tuesday, "02:00"), "phones-night"},
+		{"friday night route until saturday morning", "Phones", day(time.Saturday, "08:59"), "phones-night"},
+		{"category closed: routes for every product", "Phones", day(time.Saturday, "12:00"), "shop"},
+		{"other category", "TV", day(time.Saturday, "21:00"), "tv-saturday"},
+		{"other category closed", "TV", day(time.Monday, "12:00"), "shop"},
+		{"first open route in order", "Laptops", day(time.Monday, "09:00"), "shop-backup"},
+		{"no category", "", day(time.Monday, "12:00"), "shop"},
+		{"nothing open: fallback number", "Laptops", day(time.Monday, "23:00"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Pick(routes, tt.category, tt.at)
+			if ok != (tt.want != "") || got.Label != tt.want {
+				t.Errorf("Pick(%q, %s) = %q, %v, want %q", tt.category, tt.at.Format("Mon 15:04"), got.Label, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := Pick(nil, "Phones", day(time.Monday, "10:00")); ok {
+		t.Error("Pick without routes found one")
+	}
+}